@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/logging"
+)
+
+// aggServer collects the HTTP endpoints agg exposes, grouped by the address
+// they listen on so that metrics and health checks can share a port or not
+type aggServer struct {
+	muxes map[string]*http.ServeMux
+}
+
+func newAggServer() *aggServer {
+	return &aggServer{muxes: make(map[string]*http.ServeMux)}
+}
+
+// handle registers handler for pattern on addr. An empty addr disables the
+// endpoint.
+func (a *aggServer) handle(addr, pattern string, handler http.Handler) {
+	if addr == "" {
+		return
+	}
+	mux, ok := a.muxes[addr]
+	if !ok {
+		mux = http.NewServeMux()
+		a.muxes[addr] = mux
+	}
+	mux.Handle(pattern, handler)
+}
+
+// start listens on every address with registered endpoints and serves them
+// in the background. Listening happens up front so that a taken port fails
+// the command instead of going unnoticed.
+func (a *aggServer) start(s *state) error {
+	listeners := make(map[string]net.Listener, len(a.muxes))
+	for addr := range a.muxes {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("couldn't listen on %s: %w", addr, err)
+		}
+		listeners[addr] = ln
+	}
+
+	for addr, ln := range listeners {
+		server := &http.Server{
+			Handler:           a.muxes[addr],
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("HTTP server stopped", "address", ln.Addr().String(), "error", err)
+			}
+		}()
+		s.logger.Info("Serving HTTP endpoints", logging.Icon("📡"), "address", ln.Addr().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"strings"
+
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/migrate"
+	"github.com/AlexTLDR/gator/internal/sqlite"
+)
+
+//go:embed sql/sqlite/queries/*.sql
+var sqliteQueryFiles embed.FS
+
+// sqliteScheme prefixes a db_url naming a SQLite file rather than a Postgres
+// server, e.g. sqlite:///home/me/.gator.db
+const sqliteScheme = "sqlite://"
+
+// openDatabase connects to the database named by dbURL. It returns the
+// connection, the handle sqlc queries should run through, and the dialect
+// the schema is migrated with.
+func openDatabase(dbURL string) (*sql.DB, database.TxBeginner, migrate.Dialect, error) {
+	path, ok := strings.CutPrefix(dbURL, sqliteScheme)
+	if !ok {
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			return nil, nil, migrate.Postgres, err
+		}
+		return db, database.SQLDB(db), migrate.Postgres, nil
+	}
+
+	if path == "" {
+		return nil, nil, migrate.SQLite, errors.New("db_url needs a file path after sqlite://, e.g. sqlite:///home/me/.gator.db")
+	}
+
+	db, err := sqlite.Open(path)
+	if err != nil {
+		return nil, nil, migrate.SQLite, err
+	}
+	queries, err := sqlite.New(db, sqliteQueryFiles, "sql/sqlite/queries")
+	if err != nil {
+		db.Close()
+		return nil, nil, migrate.SQLite, err
+	}
+	return db, queries, migrate.SQLite, nil
+}
+
+// isDuplicateKey reports whether err is a unique constraint violation, as
+// worded by either backend
+func isDuplicateKey(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "duplicate key") || strings.Contains(msg, "UNIQUE constraint failed")
+}
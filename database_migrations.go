@@ -3,203 +3,197 @@ package main
 import (
 	"context"
 	"database/sql"
+	"embed"
+	"errors"
 	"fmt"
 	"log"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/migrate"
 )
 
-func ensureDatabaseMigrations(db *sql.DB) error {
-	if err := ensureUsersTable(db); err != nil {
-		return fmt.Errorf("ensure users table: %w", err)
-	}
+//go:embed sql/schema/*.sql sql/sqlite/schema/*.sql
+var schemaFiles embed.FS
+
+// legacyMarkers identifies the migrations that older versions of gator
+// applied without recording them, either through goose or by creating the
+// tables themselves on startup. Each entry is the table and, for migrations
+// that only add a column, the column whose presence shows the migration ran.
+var legacyMarkers = []struct {
+	version int64
+	table   string
+	column  string
+}{
+	{1, "users", ""},
+	{2, "feeds", ""},
+	{3, "feed_follows", ""},
+	{4, "feeds", "last_fetched_at"},
+	{5, "posts", ""},
+	{6, "post_authors", ""},
+	{7, "posts", "content"},
+	{8, "posts", "published_at_inferred"},
+	{9, "feed_fetches", ""},
+}
 
-	if err := ensureFeedsTable(db); err != nil {
-		return fmt.Errorf("ensure feeds table: %w", err)
+func loadMigrations(dialect migrate.Dialect) ([]migrate.Migration, error) {
+	if dialect == migrate.SQLite {
+		return migrate.Load(schemaFiles, "sql/sqlite/schema")
 	}
+	return migrate.Load(schemaFiles, "sql/schema")
+}
 
-	if err := ensureFeedFollowsTable(db); err != nil {
-		return fmt.Errorf("ensure feed_follows table: %w", err)
-	}
+// migrationLockTimeout is how long to wait for another gator process that is
+// migrating the same database, e.g. when several containers start at once
+const migrationLockTimeout = time.Minute
 
-	if err := ensureFeedsLastFetchedColumn(db); err != nil {
-		return fmt.Errorf("ensure feeds last_fetched_at column: %w", err)
+// openMigrator returns a Migrator for db holding the migration lock, first
+// creating schema_migrations and recording what an unversioned database
+// already has. The caller must call unlock when done.
+func openMigrator(ctx context.Context, db *sql.DB, dialect migrate.Dialect) (m *migrate.Migrator, unlock func(), err error) {
+	migrations, err := loadMigrations(dialect)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load migrations: %w", err)
 	}
+	m = migrate.New(db, dialect, migrations)
 
-	if err := ensurePostsTable(db); err != nil {
-		return fmt.Errorf("ensure posts table: %w", err)
+	unlock, err = m.Lock(ctx, migrationLockTimeout, func() {
+		log.Println("Waiting for another gator process to finish migrating the database...")
+	})
+	if errors.Is(err, migrate.ErrLockTimeout) {
+		return nil, nil, fmt.Errorf("%w; another gator process is still migrating the database, or died holding the lock (see pg_locks)", err)
 	}
-
-	return nil
-}
-
-func ensurePostsTable(db *sql.DB) error {
-	var exists bool
-	err := db.QueryRowContext(
-		context.Background(),
-		`SELECT EXISTS (
-            SELECT FROM information_schema.tables
-            WHERE table_name = 'posts'
-        )`,
-	).Scan(&exists)
 	if err != nil {
-		return fmt.Errorf("check posts table exists: %w", err)
-	}
-
-	// If table doesn't exist, create it
-	if !exists {
-		log.Println("Creating posts table...")
-		_, err = db.ExecContext(
-			context.Background(),
-			`CREATE TABLE posts (
-                id UUID PRIMARY KEY,
-                created_at TIMESTAMP NOT NULL,
-                updated_at TIMESTAMP NOT NULL,
-                title TEXT NOT NULL,
-                url TEXT NOT NULL UNIQUE,
-                description TEXT,
-                published_at TIMESTAMP,
-                feed_id UUID NOT NULL,
-                FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
-            )`,
-		)
+		return nil, nil, err
+	}
+	defer func() {
 		if err != nil {
-			return fmt.Errorf("create posts table: %w", err)
+			unlock()
 		}
-	}
+	}()
 
-	return nil
-}
-
-func ensureUsersTable(db *sql.DB) error {
-	var exists bool
-	err := db.QueryRowContext(
-		context.Background(),
-		`SELECT EXISTS (
-            SELECT FROM information_schema.tables
-            WHERE table_name = 'users'
-        )`,
-	).Scan(&exists)
+	created, err := m.Init(ctx)
 	if err != nil {
-		return fmt.Errorf("check users table exists: %w", err)
-	}
-
-	// If table doesn't exist, create it
-	if !exists {
-		log.Println("Creating users table...")
-		_, err = db.ExecContext(
-			context.Background(),
-			`CREATE TABLE users (
-                id UUID PRIMARY KEY,
-                created_at TIMESTAMP NOT NULL,
-                updated_at TIMESTAMP NOT NULL,
-                name TEXT NOT NULL UNIQUE
-            )`,
-		)
+		return nil, nil, err
+	}
+	// Only Postgres databases predate schema_migrations
+	if created && dialect == migrate.Postgres {
+		version, err := legacyVersion(ctx, db)
 		if err != nil {
-			return fmt.Errorf("create users table: %w", err)
+			return nil, nil, err
+		}
+		if version > 0 {
+			log.Printf("Recording existing schema as migration %d...", version)
+			if err := m.Baseline(ctx, version); err != nil {
+				return nil, nil, fmt.Errorf("baseline existing schema: %w", err)
+			}
 		}
 	}
 
-	return nil
+	return m, unlock, nil
 }
 
-func ensureFeedsLastFetchedColumn(db *sql.DB) error {
-	var exists bool
-	err := db.QueryRowContext(
-		context.Background(),
-		`SELECT EXISTS (
-			SELECT FROM information_schema.columns
-			WHERE table_name = 'feeds' AND column_name = 'last_fetched_at'
-		)`,
-	).Scan(&exists)
+// previewMigrator returns a Migrator for reading db's migration status
+// without changing it: no lock is taken, and an unversioned database is read
+// as if openMigrator had created schema_migrations and recorded what the
+// database already has
+func previewMigrator(ctx context.Context, db *sql.DB, dialect migrate.Dialect) (*migrate.Migrator, error) {
+	migrations, err := loadMigrations(dialect)
 	if err != nil {
-		return fmt.Errorf("check feeds last_fetched_at column exists: %w", err)
+		return nil, fmt.Errorf("load migrations: %w", err)
 	}
+	m := migrate.New(db, dialect, migrations)
 
-	// If column doesn't exist, create it
-	if !exists {
-		log.Println("Adding last_fetched_at column to feeds table...")
-		_, err = db.ExecContext(
-			context.Background(),
-			`ALTER TABLE feeds
-			ADD COLUMN last_fetched_at TIMESTAMP NULL`,
-		)
-		if err != nil {
-			return fmt.Errorf("add last_fetched_at column to feeds table: %w", err)
+	initialized, err := m.Initialized(ctx)
+	if err != nil || initialized {
+		return m, err
+	}
+
+	var version int64
+	if dialect == migrate.Postgres {
+		if version, err = legacyVersion(ctx, db); err != nil {
+			return nil, err
 		}
 	}
+	m.AssumeBaseline(version)
+	return m, nil
+}
 
-	return nil
+// runMigrations brings the database schema up to date, refusing to touch a
+// database whose recorded migrations don't match the ones built into gator
+func runMigrations(db *sql.DB, dialect migrate.Dialect) error {
+	ctx := context.Background()
+
+	m, unlock, err := openMigrator(ctx, db, dialect)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return m.Up(ctx, 0, func(mig migrate.Migration) {
+		log.Printf("Applying migration %s...", mig.Name)
+	})
 }
 
-func ensureFeedsTable(db *sql.DB) error {
-	var exists bool
-	err := db.QueryRowContext(
-		context.Background(),
-		`SELECT EXISTS (
-            SELECT FROM information_schema.tables
-            WHERE table_name = 'feeds'
-        )`,
-	).Scan(&exists)
+// checkSchema returns an error telling the user to run 'migrate up' unless
+// every migration has been applied. It never changes the database.
+func checkSchema(db *sql.DB, dialect migrate.Dialect) error {
+	ctx := context.Background()
+
+	migrations, err := loadMigrations(dialect)
 	if err != nil {
-		return fmt.Errorf("check feeds table exists: %w", err)
-	}
-
-	// If table doesn't exist, create it
-	if !exists {
-		log.Println("Creating feeds table...")
-		_, err = db.ExecContext(
-			context.Background(),
-			`CREATE TABLE feeds (
-                id UUID PRIMARY KEY,
-                created_at TIMESTAMP NOT NULL,
-                updated_at TIMESTAMP NOT NULL,
-                name TEXT NOT NULL,
-                url TEXT NOT NULL UNIQUE,
-                user_id UUID NOT NULL,
-                FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-            )`,
-		)
-		if err != nil {
-			return fmt.Errorf("create feeds table: %w", err)
-		}
+		return fmt.Errorf("load migrations: %w", err)
 	}
+	m := migrate.New(db, dialect, migrations)
 
+	initialized, err := m.Initialized(ctx)
+	if err != nil {
+		return err
+	}
+	if !initialized {
+		return errors.New("the database hasn't been set up for this version of gator; run 'gator migrate up'")
+	}
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("the database schema is %d migration(s) behind; run 'gator migrate up'", len(pending))
+	}
 	return nil
 }
 
-func ensureFeedFollowsTable(db *sql.DB) error {
-	var exists bool
-	err := db.QueryRowContext(
-		context.Background(),
-		`SELECT EXISTS (
-            SELECT FROM information_schema.tables
-            WHERE table_name = 'feed_follows'
-        )`,
-	).Scan(&exists)
-	if err != nil {
-		return fmt.Errorf("check feed_follows table exists: %w", err)
-	}
-
-	// If table doesn't exist, create it
-	if !exists {
-		log.Println("Creating feed_follows table...")
-		_, err = db.ExecContext(
-			context.Background(),
-			`CREATE TABLE feed_follows (
-                id UUID PRIMARY KEY,
-                created_at TIMESTAMP NOT NULL,
-                updated_at TIMESTAMP NOT NULL,
-                user_id UUID NOT NULL,
-                feed_id UUID NOT NULL,
-                UNIQUE(user_id, feed_id),
-                FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-                FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
-            )`,
-		)
+// legacyVersion returns the last migration an unversioned database already
+// has, or 0 for an empty database
+func legacyVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var version int64
+	for _, marker := range legacyMarkers {
+		var exists bool
+		var err error
+		if marker.column == "" {
+			err = db.QueryRowContext(ctx,
+				`SELECT EXISTS (
+                    SELECT FROM information_schema.tables
+                    WHERE table_schema = current_schema() AND table_name = $1
+                )`,
+				marker.table,
+			).Scan(&exists)
+		} else {
+			err = db.QueryRowContext(ctx,
+				`SELECT EXISTS (
+                    SELECT FROM information_schema.columns
+                    WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2
+                )`,
+				marker.table, marker.column,
+			).Scan(&exists)
+		}
 		if err != nil {
-			return fmt.Errorf("create feed_follows table: %w", err)
+			return 0, fmt.Errorf("inspect existing schema: %w", err)
+		}
+		if !exists {
+			break
 		}
+		version = marker.version
 	}
-
-	return nil
+	return version, nil
 }
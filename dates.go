@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zoneOffsets maps the timezone abbreviations seen in feeds to their UTC
+// offsets in minutes. time.Parse only resolves abbreviations known to the
+// local zone and silently treats any other as UTC, so we resolve them
+// ourselves. Ambiguous abbreviations use their most common meaning in feeds
+// (IST is India, CST is US Central).
+var zoneOffsets = map[string]int{
+	"UT":   0,
+	"UTC":  0,
+	"GMT":  0,
+	"Z":    0,
+	"WET":  0,
+	"WEST": 60,
+	"BST":  60,
+	"CET":  60,
+	"CEST": 120,
+	"MET":  60,
+	"MEST": 120,
+	"EET":  120,
+	"EEST": 180,
+	"MSK":  180,
+	"SAST": 120,
+	"IST":  330,
+	"PKT":  300,
+	"ICT":  420,
+	"WIB":  420,
+	"SGT":  480,
+	"HKT":  480,
+	"PHT":  480,
+	"AWST": 480,
+	"JST":  540,
+	"KST":  540,
+	"ACST": 570,
+	"ACDT": 630,
+	"AEST": 600,
+	"AEDT": 660,
+	"NZST": 720,
+	"NZDT": 780,
+	"BRT":  -180,
+	"ART":  -180,
+	"NST":  -210,
+	"NDT":  -150,
+	"AST":  -240,
+	"ADT":  -180,
+	"EST":  -300,
+	"EDT":  -240,
+	"CST":  -360,
+	"CDT":  -300,
+	"MST":  -420,
+	"MDT":  -360,
+	"PST":  -480,
+	"PDT":  -420,
+	"AKST": -540,
+	"AKDT": -480,
+	"HST":  -600,
+}
+
+// dateLayouts are tried in order once the weekday has been removed and any
+// zone abbreviation has been replaced with a numeric offset. Go's "2" day
+// accepts both one and two digits.
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -07:00",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04 -0700",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04 -0700",
+	"January 2, 2006 15:04:05 -0700",
+	"January 2, 2006 15:04 -0700",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"Jan 2 15:04:05 2006",
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+var (
+	leadingWeekday  = regexp.MustCompile(`^[A-Za-z]+,?\s+`)
+	trailingComment = regexp.MustCompile(`\s*\([^)]*\)$`)
+	isoWeekDate     = regexp.MustCompile(`^(\d{4})-?W(\d{2})(?:-?([1-7]))?(?:[T ](.+))?$`)
+)
+
+// parseRSSDate parses the many date formats found in RSS feeds: RFC 822 and
+// 1123 with or without weekday, seconds or a two digit day, RFC 3339 and its
+// common variants, ISO week dates, and timezone abbreviations that
+// time.Parse would otherwise misread as UTC. Dates without a zone are UTC.
+func parseRSSDate(dateStr string) (time.Time, error) {
+	s := strings.Join(strings.Fields(dateStr), " ")
+	s = trailingComment.ReplaceAllString(s, "")
+
+	if m := isoWeekDate.FindStringSubmatch(s); m != nil {
+		return parseISOWeekDate(m)
+	}
+
+	// The weekday adds nothing and is often misspelt ("Tues", "Thurs")
+	if !startsWithDigit(s) && !startsWithMonth(s) {
+		s = leadingWeekday.ReplaceAllString(s, "")
+	}
+
+	s = replaceZoneAbbreviation(s)
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse date: %s", dateStr)
+}
+
+// replaceZoneAbbreviation swaps a trailing zone abbreviation such as "CEST"
+// for its numeric offset
+func replaceZoneAbbreviation(s string) string {
+	i := strings.LastIndex(s, " ")
+	if i < 0 {
+		return s
+	}
+
+	offset, ok := zoneOffsets[strings.ToUpper(s[i+1:])]
+	if !ok {
+		return s
+	}
+
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s %s%02d%02d", s[:i], sign, offset/60, offset%60)
+}
+
+// parseISOWeekDate turns a match of isoWeekDate such as "2024-W05-3" into
+// the calendar date, with an optional time after a "T"
+func parseISOWeekDate(m []string) (time.Time, error) {
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	day := 1
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+	}
+	if week < 1 || week > 53 {
+		return time.Time{}, fmt.Errorf("invalid ISO week: %s", m[0])
+	}
+
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	weekday := int(jan4.Weekday()+6) % 7
+	date := jan4.AddDate(0, 0, -weekday+(week-1)*7+(day-1))
+
+	if m[4] == "" {
+		return date, nil
+	}
+
+	t, err := parseRSSDate(date.Format("2006-01-02") + "T" + m[4])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse time in ISO week date: %s", m[0])
+	}
+	return t, nil
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// startsWithMonth reports whether s begins with a month name, as in
+// "Jan 2, 2006", so it isn't mistaken for a weekday
+func startsWithMonth(s string) bool {
+	word := strings.ToLower(strings.TrimRight(strings.SplitN(s, " ", 2)[0], ","))
+	if len(word) < 3 {
+		return false
+	}
+	for m := time.January; m <= time.December; m++ {
+		if strings.HasPrefix(strings.ToLower(m.String()), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateFlag parses a date such as 2026-09-01, today or yesterday in loc,
+// or an age such as 30d, 2w or 12h counted back from now. A date given as an
+// upper bound includes the whole day.
+func parseDateFlag(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	switch strings.ToLower(value) {
+	case "today":
+		value = time.Now().In(loc).Format("2006-01-02")
+	case "yesterday":
+		value = time.Now().In(loc).AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t.UTC(), nil
+	}
+
+	// time.ParseDuration stops at hours, so days and weeks are handled here
+	if n := len(value); n > 1 && (value[n-1] == 'd' || value[n-1] == 'w') {
+		if days, err := strconv.Atoi(value[:n-1]); err == nil && days >= 0 {
+			if value[n-1] == 'w' {
+				days *= 7
+			}
+			return time.Now().UTC().AddDate(0, 0, -days), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().UTC().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither a date like 2026-09-01, today or yesterday, nor an age like 30d, 2w or 12h", value)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRSSDate(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want time.Time
+	}{
+		// RFC 822 and 1123
+		{"RFC 1123 with GMT", "Mon, 02 Jan 2006 15:04:05 GMT", utc(2006, 1, 2, 15, 4, 5)},
+		{"RFC 1123 with numeric zone", "Mon, 02 Jan 2006 15:04:05 +0200", utc(2006, 1, 2, 13, 4, 5)},
+		{"RFC 822 two-digit year", "02 Jan 06 15:04 -0500", utc(2006, 1, 2, 20, 4, 0)},
+		{"colon in numeric zone", "02 Jan 2006 15:04:05 +05:30", utc(2006, 1, 2, 9, 34, 5)},
+		{"full month name", "2 January 2006 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Zone abbreviations time.Parse would read as UTC
+		{"EST", "Mon, 02 Jan 2006 15:04:05 EST", utc(2006, 1, 2, 20, 4, 5)},
+		{"EDT", "Sun, 02 Jul 2006 15:04:05 EDT", utc(2006, 7, 2, 19, 4, 5)},
+		{"PST", "Mon, 02 Jan 2006 15:04:05 PST", utc(2006, 1, 2, 23, 4, 5)},
+		{"CEST", "Sun, 02 Jul 2006 15:04:05 CEST", utc(2006, 7, 2, 13, 4, 5)},
+		{"IST is India", "Mon, 02 Jan 2006 15:04:05 IST", utc(2006, 1, 2, 9, 34, 5)},
+		{"NST half hour west", "Mon, 02 Jan 2006 15:04:05 NST", utc(2006, 1, 2, 18, 34, 5)},
+		{"lower-case abbreviation", "Mon, 02 Jan 2006 15:04:05 jst", utc(2006, 1, 2, 6, 4, 5)},
+		{"UT", "02 Jan 2006 15:04:05 UT", utc(2006, 1, 2, 15, 4, 5)},
+		{"Z", "02 Jan 2006 15:04:05 Z", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Single-digit days and missing seconds
+		{"single-digit day", "Mon, 2 Jan 2006 15:04:05 GMT", utc(2006, 1, 2, 15, 4, 5)},
+		{"missing seconds", "Mon, 02 Jan 2006 15:04 GMT", utc(2006, 1, 2, 15, 4, 0)},
+		{"single-digit day and missing seconds", "Mon, 2 Jan 2006 9:04 +0100", utc(2006, 1, 2, 8, 4, 0)},
+		{"no zone is UTC", "2 Jan 2006 15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+		{"date only", "2 Jan 2006", utc(2006, 1, 2, 0, 0, 0)},
+
+		// Weekdays, misspelt or missing
+		{"no weekday", "02 Jan 2006 15:04:05 GMT", utc(2006, 1, 2, 15, 4, 5)},
+		{"long weekday", "Monday, 02 Jan 2006 15:04:05 GMT", utc(2006, 1, 2, 15, 4, 5)},
+		{"misspelt weekday", "Tues, 03 Jan 2006 15:04:05 GMT", utc(2006, 1, 3, 15, 4, 5)},
+		{"weekday without comma", "Thurs 05 Jan 2006 15:04:05 GMT", utc(2006, 1, 5, 15, 4, 5)},
+		{"wrong weekday is ignored", "Fri, 02 Jan 2006 15:04:05 GMT", utc(2006, 1, 2, 15, 4, 5)},
+
+		// US style
+		{"month first", "Jan 2, 2006 15:04:05 -0700", utc(2006, 1, 2, 22, 4, 5)},
+		{"month first date only", "January 2, 2006", utc(2006, 1, 2, 0, 0, 0)},
+		{"weekday then month", "Monday, January 2, 2006", utc(2006, 1, 2, 0, 0, 0)},
+		{"ANSI C", "Jan 2 15:04:05 2006", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Extra whitespace and comments
+		{"extra whitespace", "  Mon,  02 Jan 2006\t15:04:05   GMT ", utc(2006, 1, 2, 15, 4, 5)},
+		{"trailing comment", "Mon, 02 Jan 2006 15:04:05 -0500 (EST)", utc(2006, 1, 2, 20, 4, 5)},
+
+		// RFC 3339 and dc:date variants
+		{"RFC 3339", "2006-01-02T15:04:05Z", utc(2006, 1, 2, 15, 4, 5)},
+		{"RFC 3339 with offset", "2006-01-02T15:04:05+01:00", utc(2006, 1, 2, 14, 4, 5)},
+		{"RFC 3339 with fraction", "2006-01-02T15:04:05.123Z", time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC)},
+		{"dc:date without seconds", "2006-01-02T15:04+02:00", utc(2006, 1, 2, 13, 4, 0)},
+		{"offset without colon", "2006-01-02T15:04:05-0700", utc(2006, 1, 2, 22, 4, 5)},
+		{"no zone", "2006-01-02T15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+		{"space separator", "2006-01-02 15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+		{"dc:date date only", "2006-01-02", utc(2006, 1, 2, 0, 0, 0)},
+		{"slashes", "2006/01/02 15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+
+		// ISO week dates
+		{"ISO week date", "2024-W05-3", utc(2024, 1, 31, 0, 0, 0)},
+		{"ISO week date compact", "2024W053", utc(2024, 1, 31, 0, 0, 0)},
+		{"ISO week without day is Monday", "2024-W05", utc(2024, 1, 29, 0, 0, 0)},
+		{"ISO week 1 starting in the previous year", "2020-W01-1", utc(2019, 12, 30, 0, 0, 0)},
+		{"ISO week 53", "2020-W53-7", utc(2021, 1, 3, 0, 0, 0)},
+		{"ISO week date with time", "2024-W05-3T10:30:00Z", utc(2024, 1, 31, 10, 30, 0)},
+		{"ISO week date with offset", "2024-W05-3T10:30+01:00", utc(2024, 1, 31, 9, 30, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRSSDate(tt.in)
+			if err != nil {
+				t.Fatalf("parseRSSDate(%q) failed: %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseRSSDate(%q) = %v, want %v", tt.in, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRSSDateErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"not a date",
+		"Mon, 32 Jan 2006 15:04:05 GMT",
+		"02 Foo 2006 15:04:05 GMT",
+		"2024-W00-1",
+		"2024-W54-1",
+		"2024-W05-3Tnoon",
+	} {
+		if got, err := parseRSSDate(in); err == nil {
+			t.Errorf("parseRSSDate(%q) = %v, want an error", in, got)
+		}
+	}
+}
+
+func TestReplaceZoneAbbreviation(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"02 Jan 2006 15:04 EST", "02 Jan 2006 15:04 -0500"},
+		{"02 Jan 2006 15:04 IST", "02 Jan 2006 15:04 +0530"},
+		{"02 Jan 2006 15:04 NDT", "02 Jan 2006 15:04 -0230"},
+		{"02 Jan 2006 15:04 GMT", "02 Jan 2006 15:04 +0000"},
+		{"02 Jan 2006 15:04 +0100", "02 Jan 2006 15:04 +0100"},
+		{"02 Jan 2006 15:04 XYZ", "02 Jan 2006 15:04 XYZ"},
+		{"2006-01-02", "2006-01-02"},
+	}
+	for _, tt := range tests {
+		if got := replaceZoneAbbreviation(tt.in); got != tt.want {
+			t.Errorf("replaceZoneAbbreviation(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateFlag(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		value    string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"date is midnight in the user's zone", "2026-09-01", false, time.Date(2026, 9, 1, 4, 0, 0, 0, time.UTC)},
+		{"date as an upper bound includes the whole day", "2026-09-01", true, time.Date(2026, 9, 2, 4, 0, 0, 0, time.UTC)},
+		{"upper bound across a DST change", "2026-11-01", true, time.Date(2026, 11, 2, 5, 0, 0, 0, time.UTC)},
+		{"today", "today", false, today.UTC()},
+		{"today in capitals", "TODAY", false, today.UTC()},
+		{"today as an upper bound", "today", true, today.AddDate(0, 0, 1).UTC()},
+		{"yesterday", "yesterday", false, today.AddDate(0, 0, -1).UTC()},
+		{"yesterday as an upper bound ends today", "yesterday", true, today.UTC()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDateFlag(tt.value, loc, tt.endOfDay)
+			if err != nil {
+				t.Fatalf("parseDateFlag(%q) failed: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("parseDateFlag(%q, %v) = %v, want %v", tt.value, tt.endOfDay, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateFlagAges(t *testing.T) {
+	tests := []struct {
+		value string
+		age   time.Duration
+	}{
+		{"0d", 0},
+		{"30d", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		for _, endOfDay := range []bool{false, true} {
+			before := time.Now().UTC()
+			got, err := parseDateFlag(tt.value, time.UTC, endOfDay)
+			after := time.Now().UTC()
+			if err != nil {
+				t.Fatalf("parseDateFlag(%q) failed: %v", tt.value, err)
+			}
+
+			// Ages count back from now whether or not they're an upper
+			// bound. Days are calendar days, so allow for a DST change.
+			lo, hi := before.Add(-tt.age-time.Hour), after.Add(-tt.age+time.Hour)
+			if got.Before(lo) || got.After(hi) {
+				t.Errorf("parseDateFlag(%q, %v) = %v, want about %v ago", tt.value, endOfDay, got, tt.age)
+			}
+		}
+	}
+}
+
+func TestParseDateFlagErrors(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"d",
+		"w",
+		"-3d",
+		"-1h",
+		"2w3d",
+		"3 days",
+		"2026-13-01",
+		"2026-02-30",
+		"01/09/2026",
+		"tomorrow",
+	} {
+		if got, err := parseDateFlag(value, time.UTC, false); err == nil {
+			t.Errorf("parseDateFlag(%q) = %v, want an error", value, got)
+		}
+	}
+}
@@ -8,11 +8,17 @@ import (
 	"fmt"
 	"html"
 	"io"
-	"net/http"
+	"log/slog"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/AlexTLDR/gator/internal/article"
 	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/health"
+	"github.com/AlexTLDR/gator/internal/htmltext"
+	"github.com/AlexTLDR/gator/internal/logging"
+	"github.com/AlexTLDR/gator/internal/metrics"
 	"github.com/google/uuid"
 )
 
@@ -33,45 +39,52 @@ type RSSChannel struct {
 }
 
 type RSSItem struct {
-	Title       string   `xml:"title"`
-	Link        string   `xml:"link"`
-	Description string   `xml:"description"`
-	PubDate     string   `xml:"pubDate"`
-	GUID        string   `xml:"guid"`
-	Categories  []string `xml:"category"`
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	Description string      `xml:"description"`
+	PubDate     string      `xml:"pubDate"`
+	DCDate      string      `xml:"http://purl.org/dc/elements/1.1/ date"`
+	GUID        string      `xml:"guid"`
+	Categories  []string    `xml:"category"`
+	Authors     []RSSAuthor `xml:"author"`
+	Creators    []string    `xml:"http://purl.org/dc/elements/1.1/ creator"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+// Date returns the item's publication date as found in the feed, preferring
+// <pubDate> over <dc:date>
+func (item RSSItem) Date() string {
+	if strings.TrimSpace(item.PubDate) != "" {
+		return item.PubDate
 	}
-	
-	fmt.Printf("🌐 Fetching URL: %s\n", feedURL)
+	return item.DCDate
+}
 
-	req.Header.Set("User-Agent", "gator")
+// RSSAuthor covers both the RSS <author> element, which holds text such as
+// "jane@example.com (Jane Doe)", and the Atom <author> element with
+// <name> and <email> children.
+type RSSAuthor struct {
+	Text  string `xml:",chardata"`
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching feed: %w", err)
-	}
-	defer resp.Body.Close()
+// postAuthor is a normalized author ready to be stored.
+type postAuthor struct {
+	Name  string
+	Email string
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+func fetchFeed(ctx context.Context, logger *slog.Logger, feedURL string) (*RSSFeed, fetchResponse, error) {
+	logger.Debug("Fetching URL", logging.Icon("🌐"), "url", feedURL)
 
-	body, err := io.ReadAll(resp.Body)
+	resp, err := fetchURL(ctx, feedURL)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, resp, err
 	}
 
 	var feed RSSFeed
-	if err := xml.Unmarshal(body, &feed); err != nil {
-		return nil, fmt.Errorf("error parsing feed: %w", err)
+	if err := xml.Unmarshal(resp.Body, &feed); err != nil {
+		return nil, resp, fmt.Errorf("error parsing feed: %w", err)
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
@@ -79,10 +92,13 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 
 	for i := range feed.Channel.Items {
 		feed.Channel.Items[i].Title = html.UnescapeString(feed.Channel.Items[i].Title)
-		feed.Channel.Items[i].Description = html.UnescapeString(feed.Channel.Items[i].Description)
+		feed.Channel.Items[i].Description = htmltext.Sanitize(html.UnescapeString(feed.Channel.Items[i].Description))
+		for j := range feed.Channel.Items[i].Categories {
+			feed.Channel.Items[i].Categories[j] = html.UnescapeString(feed.Channel.Items[i].Categories[j])
+		}
 	}
 
-	return &feed, nil
+	return &feed, resp, nil
 }
 
 func scrapeFeeds(s *state) error {
@@ -97,6 +113,22 @@ func scrapeFeeds(s *state) error {
 		return fmt.Errorf("error getting next feed to fetch: %w", err)
 	}
 
+	_, err = scrapeFeed(ctx, s, feed)
+	return err
+}
+
+// scrapeResult summarizes one fetch of a feed
+type scrapeResult struct {
+	ItemsSeen     int
+	ItemsInserted int
+	NewPosts      []database.CreatePostRow
+}
+
+// scrapeFeed fetches a single feed, saves its new posts and records the
+// fetch in the feed's fetch history
+func scrapeFeed(ctx context.Context, s *state, feed database.Feed) (result scrapeResult, err error) {
+	logger := s.logger.With("feed_id", feed.ID, "feed_url", feed.Url)
+
 	// Mark the feed as fetched
 	now := time.Now().UTC()
 	err = s.db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
@@ -104,189 +136,555 @@ func scrapeFeeds(s *state) error {
 		ID:            feed.ID,
 	})
 	if err != nil {
-		return fmt.Errorf("error marking feed as fetched: %w", err)
+		return result, fmt.Errorf("error marking feed as fetched: %w", err)
+	}
+
+	var resp fetchResponse
+	fetch, recordErr := s.db.CreateFeedFetch(ctx, database.CreateFeedFetchParams{
+		ID:        uuid.New(),
+		FeedID:    feed.ID,
+		StartedAt: now,
+	})
+	if recordErr != nil {
+		logger.Warn("Could not record fetch history", "error", recordErr)
+	} else {
+		defer func() {
+			finishFeedFetch(ctx, logger, s, fetch.ID, resp, result, err)
+		}()
 	}
 
 	// Fetch the feed content
-	fmt.Printf("\n===== [%s] =====\n", now.Format(time.RFC3339))
-	fmt.Printf("📥 Fetching feed: %s\n", feed.Name)
-	fmt.Printf("🔗 URL: %s\n", feed.Url)
+	logger.Info("Fetching feed", logging.Icon("📥"), "feed_name", feed.Name)
 	
 	if feed.LastFetchedAt.Valid {
-		fmt.Printf("🕒 Last fetched: %s (%.1f hours ago)\n", 
-			feed.LastFetchedAt.Time.Format(time.RFC3339),
-			now.Sub(feed.LastFetchedAt.Time).Hours())
+		logger.Info("Last fetched", logging.Icon("🕒"),
+			"last_fetched_at", feed.LastFetchedAt.Time,
+			"since", now.Sub(feed.LastFetchedAt.Time).Round(time.Second))
 	} else {
-		fmt.Printf("🕒 Last fetched: Never\n")
+		logger.Info("Last fetched", logging.Icon("🕒"), "last_fetched_at", "never")
 	}
 	
-	rssFeed, err := fetchFeed(ctx, feed.Url)
+	fetchStart := time.Now()
+	rssFeed, resp, err := fetchFeed(ctx, logger, feed.Url)
+	metrics.FetchFinished(resp.StatusCode, time.Since(fetchStart), err)
 	if err != nil {
-		return fmt.Errorf("error fetching feed content: %w", err)
+		return result, fmt.Errorf("error fetching feed content: %w", err)
 	}
 
-	// Print feed metadata
-	fmt.Printf("📰 Title: %s\n", rssFeed.Channel.Title)
+	// Log feed metadata
+	logger.Debug("Feed title", logging.Icon("📰"), "title", rssFeed.Channel.Title)
 	if rssFeed.Channel.Description != "" {
-		fmt.Printf("📝 Description: %s\n", rssFeed.Channel.Description)
+		logger.Debug("Feed description", logging.Icon("📝"), "description", rssFeed.Channel.Description)
 	}
 	
-	// Print feed items
-	fmt.Printf("📚 Found %d items in feed\n", len(rssFeed.Channel.Items))
-	fmt.Println("----------------------------")
-	
-	// Count how many new posts we save
-	newPostsCount := 0
+	result.ItemsSeen = len(rssFeed.Channel.Items)
+	logger.Info("Found items in feed", logging.Icon("📚"), "items", result.ItemsSeen)
 	
-	for i, item := range rssFeed.Channel.Items {
-		pubDate := item.PubDate
-		if pubDate == "" {
-			pubDate = "No date"
-		}
-		
-		// Print the item
-		fmt.Printf("%d. [%s] %s\n", i+1, pubDate, item.Title)
-		fmt.Printf("   🔗 %s\n", item.Link)
-		if len(item.Categories) > 0 {
-			fmt.Printf("   🏷️  %s\n", strings.Join(item.Categories, ", "))
-		}
-		
+	// Items without a usable date get the channel's build date, or the time
+	// we first saw them
+	fallbackDate := now
+	if buildDate, err := parseRSSDate(rssFeed.Channel.LastBuildDate); err == nil {
+		fallbackDate = buildDate
+	}
+
+	for _, item := range rssFeed.Channel.Items {
 		// Save the post to the database
-		err = savePost(ctx, s, item, feed.ID)
+		post, err := savePost(ctx, s, logger, item, feed, fallbackDate)
 		if err != nil {
-			// If it's a duplicate, just skip it silently
-			if strings.Contains(err.Error(), "duplicate key") {
-				fmt.Printf("   ⚠️ Post already exists in database\n")
+			// Duplicates are expected on every fetch after the first
+			if isDuplicateKey(err) {
+				logger.Debug("Post already exists", "title", item.Title, "url", item.Link)
+				metrics.DuplicateItem()
+			} else if errors.Is(err, errExpired) {
+				logger.Debug("Post is too old to keep", "title", item.Title, "url", item.Link)
 			} else {
-				fmt.Printf("   ❌ Error saving post: %v\n", err)
+				logger.Warn("Could not save post", "title", item.Title, "url", item.Link, "error", err)
 			}
-		} else {
-			fmt.Printf("   ✅ Post saved to database\n")
-			newPostsCount++
+			continue
 		}
-		
-		fmt.Println()
+
+		var authors []string
+		for _, a := range itemAuthors(item) {
+			authors = append(authors, a.Name)
+		}
+
+		logger.Info("Saved post", logging.Icon("✅"),
+			"title", post.Title,
+			"url", post.Url,
+			"published_at", post.PublishedAt.Time,
+			"categories", strings.Join(item.Categories, ", "),
+			"authors", strings.Join(authors, ", "),
+		)
+		result.ItemsInserted++
+		result.NewPosts = append(result.NewPosts, post)
+		metrics.PostInserted()
 	}
 	
-	fmt.Printf("===========================\n")
-	fmt.Printf("📊 Saved %d new posts from this feed\n", newPostsCount)
-	fmt.Println("===========================")
+	logger.Info("Saved new posts from this feed", logging.Icon("📊"), "new_posts", result.ItemsInserted)
 
-	return nil
+	return result, nil
+}
+
+// finishFeedFetch completes a fetch history record with the outcome of the
+// fetch
+func finishFeedFetch(ctx context.Context, logger *slog.Logger, s *state, fetchID uuid.UUID, resp fetchResponse, result scrapeResult, fetchErr error) {
+	params := database.FinishFeedFetchParams{
+		ID:            fetchID,
+		FinishedAt:    sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		HttpStatus:    sql.NullInt32{Int32: int32(resp.StatusCode), Valid: resp.StatusCode != 0},
+		Bytes:         int64(len(resp.Body)),
+		ItemsSeen:     int32(result.ItemsSeen),
+		ItemsInserted: int32(result.ItemsInserted),
+	}
+	if fetchErr != nil {
+		params.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	}
+
+	if err := s.db.FinishFeedFetch(ctx, params); err != nil {
+		logger.Warn("Could not record fetch history", "error", err)
+	}
 }
 
-// savePost saves a single RSS item as a post in the database
-func savePost(ctx context.Context, s *state, item RSSItem, feedID uuid.UUID) error {
+// savePost saves a single RSS item as a post in the database. Items whose
+// date is missing or unparseable are stored with fallbackDate and marked as
+// having an inferred date.
+func savePost(ctx context.Context, s *state, logger *slog.Logger, item RSSItem, feed database.Feed, fallbackDate time.Time) (database.CreatePostRow, error) {
 	if item.Link == "" {
-		return errors.New("post has no URL")
+		return database.CreatePostRow{}, errors.New("post has no URL")
 	}
 	
 	if item.Title == "" {
-		return errors.New("post has no title")
+		return database.CreatePostRow{}, errors.New("post has no title")
 	}
 	
 	// Parse the published date
-	var publishedAt sql.NullTime
-	if item.PubDate != "" {
-		// Try multiple date formats
-		parsedTime, err := parseRSSDate(item.PubDate)
+	publishedAt := sql.NullTime{Time: fallbackDate, Valid: true}
+	inferred := true
+	if date := item.Date(); date != "" {
+		parsedTime, err := parseRSSDate(date)
 		if err == nil {
-			publishedAt = sql.NullTime{
-				Time:  parsedTime,
-				Valid: true,
-			}
+			publishedAt.Time = parsedTime
+			inferred = false
 		} else {
-			fmt.Printf("   ⚠️ Could not parse date '%s': %v\n", item.PubDate, err)
-			// Use current time as fallback
-			publishedAt = sql.NullTime{
-				Time:  time.Now().UTC(),
-				Valid: true,
-			}
-		}
-	} else {
-		// If no date provided, use current time
-		publishedAt = sql.NullTime{
-			Time:  time.Now().UTC(),
-			Valid: true,
+			logger.Warn("Could not parse date, using fallback", "date", date, "fallback", fallbackDate, "url", item.Link)
 		}
 	}
+	// Feeds use all sorts of offsets; store every date in UTC
+	publishedAt.Time = publishedAt.Time.UTC()
+
+	expired, err := expiresImmediately(ctx, s, feed, publishedAt, inferred)
+	if err != nil {
+		return database.CreatePostRow{}, fmt.Errorf("error checking retention: %w", err)
+	}
+	if expired {
+		return database.CreatePostRow{}, errExpired
+	}
 	
-	// Create the post
+	// Create the post with its categories and authors, so that a post is
+	// never left without them
 	now := time.Now().UTC()
-	_, err := s.db.CreatePost(ctx, database.CreatePostParams{
-		ID:          uuid.New(),
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		Title:       item.Title,
-		Url:         item.Link,
-		Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
-		PublishedAt: publishedAt,
-		FeedID:      feedID,
+	var post database.CreatePostRow
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		post, err = q.CreatePost(ctx, database.CreatePostParams{
+			ID:                  uuid.New(),
+			CreatedAt:           now,
+			UpdatedAt:           now,
+			Title:               item.Title,
+			Url:                 item.Link,
+			Description:         sql.NullString{String: item.Description, Valid: item.Description != ""},
+			PublishedAt:         publishedAt,
+			PublishedAtInferred: inferred,
+			FeedID:              feed.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := savePostCategories(ctx, q, post.ID, item.Categories); err != nil {
+			return fmt.Errorf("error saving categories: %w", err)
+		}
+
+		if err := savePostAuthors(ctx, q, post.ID, itemAuthors(item)); err != nil {
+			return fmt.Errorf("error saving authors: %w", err)
+		}
+		return nil
 	})
-	
-	return err
+	if err != nil {
+		return database.CreatePostRow{}, err
+	}
+
+	// The article is fetched once the post is saved, so that the transaction
+	// isn't held open while waiting on the publisher
+	if feed.FetchFullArticle {
+		saveFullArticle(ctx, s, logger, post)
+	}
+
+	return post, nil
+}
+
+// saveFullArticle downloads the page a post links to and stores its main
+// content. Failures are reported but don't stop the post from being saved,
+// since the feed's own description is still there to fall back on.
+func saveFullArticle(ctx context.Context, s *state, logger *slog.Logger, post database.CreatePostRow) {
+	page, err := fetchURL(ctx, post.Url)
+	if err != nil {
+		logger.Warn("Could not fetch full article", "url", post.Url, "error", err)
+		return
+	}
+
+	content, err := article.Extract(page.Body, post.Url)
+	if err != nil {
+		logger.Warn("Could not extract full article", "url", post.Url, "error", err)
+		return
+	}
+
+	err = s.db.UpdatePostContent(ctx, database.UpdatePostContentParams{
+		Content:   sql.NullString{String: content, Valid: true},
+		UpdatedAt: time.Now().UTC(),
+		ID:        post.ID,
+	})
+	if err != nil {
+		logger.Warn("Could not save full article", "url", post.Url, "error", err)
+		return
+	}
+
+	logger.Debug("Full article saved", logging.Icon("📄"), "url", post.Url)
 }
 
-// parseRSSDate tries to parse a date string from an RSS feed in various formats
-func parseRSSDate(dateStr string) (time.Time, error) {
-	formats := []string{
-		time.RFC1123Z,     // "Mon, 02 Jan 2006 15:04:05 -0700"
-		time.RFC1123,      // "Mon, 02 Jan 2006 15:04:05 MST"
-		time.RFC822Z,      // "02 Jan 06 15:04 -0700"
-		time.RFC822,       // "02 Jan 06 15:04 MST"
-		time.RFC3339,      // "2006-01-02T15:04:05Z07:00"
-		"2006-01-02T15:04:05-07:00",
-		"2006-01-02 15:04:05 -0700",
-		"2006-01-02 15:04:05",
-		"2006-01-02",
-		"02 Jan 2006 15:04:05 MST",
-		"02 Jan 2006 15:04:05 -0700",
-		"Mon, 2 Jan 2006 15:04:05 MST",
-		"Mon, 2 Jan 2006 15:04:05 -0700",
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return t, nil
+// savePostCategories links a post to its categories, creating any category
+// that hasn't been seen before
+func savePostCategories(ctx context.Context, q database.Querier, postID uuid.UUID, categories []string) error {
+	seen := make(map[string]bool)
+	for _, raw := range categories {
+		name := normalizeCategory(raw)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		category, err := q.UpsertCategory(ctx, database.UpsertCategoryParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			Name:      name,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.AddPostCategory(ctx, database.AddPostCategoryParams{
+			PostID:     postID,
+			CategoryID: category.ID,
+		})
+		if err != nil {
+			return err
 		}
 	}
-	
-	return time.Time{}, fmt.Errorf("could not parse date: %s", dateStr)
+
+	return nil
+}
+
+// savePostAuthors links a post to its authors, creating any author that
+// hasn't been seen before
+func savePostAuthors(ctx context.Context, q database.Querier, postID uuid.UUID, authors []postAuthor) error {
+	for _, a := range authors {
+		author, err := q.UpsertAuthor(ctx, database.UpsertAuthorParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			Name:      a.Name,
+			Email:     a.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.AddPostAuthor(ctx, database.AddPostAuthorParams{
+			PostID:   postID,
+			AuthorID: author.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizeCategory lowercases a category and collapses its whitespace so
+// that "Go", " go " and "GO" end up as the same tag
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.Join(strings.Fields(category), " "))
+}
+
+// itemAuthors collects the authors of an item from <author>, <dc:creator>
+// and Atom <author> elements, dropping duplicates
+func itemAuthors(item RSSItem) []postAuthor {
+	var authors []postAuthor
+	seen := make(map[postAuthor]bool)
+
+	add := func(a postAuthor) {
+		a.Name = strings.Join(strings.Fields(html.UnescapeString(a.Name)), " ")
+		a.Email = strings.ToLower(strings.TrimSpace(a.Email))
+		if a.Name == "" {
+			a.Name = a.Email
+		}
+		if a.Name == "" || seen[a] {
+			return
+		}
+		seen[a] = true
+		authors = append(authors, a)
+	}
+
+	for _, a := range item.Authors {
+		if strings.TrimSpace(a.Name) != "" || strings.TrimSpace(a.Email) != "" {
+			add(postAuthor{Name: a.Name, Email: a.Email})
+			continue
+		}
+		add(parseRSSAuthor(a.Text))
+	}
+
+	for _, creator := range item.Creators {
+		add(postAuthor{Name: creator})
+	}
+
+	return authors
+}
+
+// parseRSSAuthor splits an RSS author string such as
+// "jane@example.com (Jane Doe)" into a name and an email address
+func parseRSSAuthor(text string) postAuthor {
+	text = strings.TrimSpace(text)
+
+	if open := strings.Index(text, "("); open > 0 && strings.HasSuffix(text, ")") {
+		email := strings.TrimSpace(text[:open])
+		name := strings.TrimSpace(text[open+1 : len(text)-1])
+		if strings.Contains(email, "@") {
+			return postAuthor{Name: name, Email: email}
+		}
+	}
+
+	if strings.Contains(text, "@") && !strings.Contains(text, " ") {
+		return postAuthor{Email: text}
+	}
+
+	return postAuthor{Name: text}
 }
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %v <time_between_reqs>", cmd.Name)
+	fs := newFlagSet(cmd)
+	once := fs.Bool("once", false, "fetch every due feed once and exit")
+	due := fs.Duration("due", 0, "with --once, only fetch feeds not fetched within this long")
+	metricsAddr := fs.String("metrics-addr", s.cfg.MetricsAddr, "serve Prometheus metrics on this address, e.g. :9090")
+	healthAddr := fs.String("health-addr", s.cfg.HealthAddr, "serve /healthz and /readyz on this address, e.g. :8080")
+	readyIntervals := fs.Int("ready-intervals", 3, "report not ready after this many intervals without a successful cycle")
+	logFlags := addLogFlags(fs)
+
+	usage := fmt.Errorf("usage: %v <time_between_reqs> [--metrics-addr <addr>] [--health-addr <addr>] [--ready-intervals <n>] | %v --once [--due <duration>] [feed url|name...] [--log-format pretty|text|json|quiet] [--log-level <level>]", cmd.Name, cmd.Name)
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return usage
+	}
+
+	if err := logFlags.apply(s); err != nil {
+		return err
+	}
+
+	if *once {
+		return aggregateOnce(s, args, *due)
+	}
+	if s.output != outputText {
+		return fmt.Errorf("--output %s only works with --once", s.output)
+	}
+
+	if len(args) != 1 {
+		return usage
 	}
 
 	// Parse the time duration
-	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
+	timeBetweenRequests, err := time.ParseDuration(args[0])
 	if err != nil {
 		return fmt.Errorf("invalid duration format: %w (examples: 30s, 1m, 5m, 1h)", err)
 	}
 
-	fmt.Printf("🚀 Starting feed aggregation\n")
-	fmt.Printf("⏱️  Collecting feeds every %s\n", timeBetweenRequests)
-	fmt.Printf("📊 Feed collection started at: %s\n", time.Now().Format(time.RFC3339))
-	fmt.Printf("❗ Press Ctrl+C to stop\n\n")
+	if *readyIntervals < 1 {
+		return fmt.Errorf("--ready-intervals must be at least 1")
+	}
+
+	checker := health.NewChecker(s.conn, timeBetweenRequests, *readyIntervals)
+
+	server := newAggServer()
+	server.handle(*metricsAddr, "/metrics", metrics.Handler())
+	server.handle(*healthAddr, "/healthz", checker.HealthzHandler())
+	server.handle(*healthAddr, "/readyz", checker.ReadyzHandler())
+	if err := server.start(s); err != nil {
+		return err
+	}
+
+	// Tell systemd we're up, and keep its watchdog fed while cycles succeed
+	if _, err := health.Notify("READY=1"); err != nil {
+		s.logger.Warn("Could not notify systemd", "error", err)
+	}
+	if interval, ok := health.WatchdogInterval(); ok {
+		go checker.Watchdog(context.Background(), interval/2)
+		s.logger.Info("Feeding systemd watchdog", logging.Icon("🐶"), "timeout", interval)
+	}
+
+	s.logger.Info("Starting feed aggregation", logging.Icon("🚀"), "interval", timeBetweenRequests)
+	s.logger.Info("Press Ctrl+C to stop", logging.Icon("❗"))
 
 	// Create a ticker that triggers every timeBetweenRequests
 	ticker := time.NewTicker(timeBetweenRequests)
 	defer ticker.Stop()
 	
 	count := 0
+	var lastPurge time.Time
 	// Run immediately and then on each tick
 	for {
 		count++
-		fmt.Printf("🔄 Aggregation cycle #%d\n", count)
+		s.logger.Info("Aggregation cycle", logging.Icon("🔄"), "cycle", count)
+
+		due, err := s.db.CountFeedsDueForFetch(context.Background(), sql.NullTime{Time: time.Now().UTC().Add(-timeBetweenRequests), Valid: true})
+		if err != nil {
+			s.logger.Warn("Could not count feeds due for fetching", "error", err)
+		} else {
+			metrics.SetFeedsDue(int(due))
+		}
 		
-		if err := scrapeFeeds(s); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			fmt.Println("⏳ Will try again on next tick...")
+		err = scrapeFeeds(s)
+		if err != nil {
+			s.logger.Error("Aggregation cycle failed, will try again on next tick", "cycle", count, "error", err)
+		}
+		checker.CycleCompleted(err)
+
+		if time.Since(lastPurge) >= purgeInterval {
+			purgeExpired(context.Background(), s)
+			lastPurge = time.Now()
 		}
 		
-		fmt.Printf("⏳ Waiting %s until next fetch...\n\n", timeBetweenRequests)
+		s.logger.Debug("Waiting until next fetch", logging.Icon("⏳"), "interval", timeBetweenRequests)
 		// Wait for next tick
 		<-ticker.C
 	}
 }
+
+func handlerRefresh(s *state, cmd command) error {
+	fs := newFlagSet(cmd)
+	logFlags := addLogFlags(fs)
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %v <feed url|name> [--log-format pretty|text|json|quiet] [--log-level <level>]", cmd.Name)
+	}
+
+	if err := logFlags.apply(s); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	feed, err := findFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := scrapeFeed(ctx, s, feed)
+	if err != nil {
+		return err
+	}
+
+	if len(result.NewPosts) == 0 {
+		fmt.Printf("No new posts in '%s'\n", feed.Name)
+		return nil
+	}
+
+	fmt.Printf("New posts in '%s':\n", feed.Name)
+	for _, post := range result.NewPosts {
+		fmt.Printf(" * %s\n", post.Title)
+		fmt.Printf("   %s\n", post.Url)
+	}
+
+	return nil
+}
+
+// aggSummary is what agg --once prints when it's done
+type aggSummary struct {
+	Feeds     int `json:"feeds"`
+	Fetched   int `json:"fetched"`
+	Failed    int `json:"failed"`
+	ItemsSeen int `json:"items_seen"`
+	NewPosts  int `json:"new_posts"`
+}
+
+// printAggSummary writes the summary to w in the given output format. It's
+// printed rather than logged so that --log-format doesn't hide it or turn it
+// into log records.
+func printAggSummary(w io.Writer, format outputFormat, summary aggSummary) error {
+	if format != outputText {
+		return printRecords(w, format, []aggSummary{summary})
+	}
+	if summary.Feeds == 0 {
+		_, err := fmt.Fprintln(w, "✅ No feeds are due for fetching")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "📥 Feeds fetched: %d\n📚 Items seen: %d\n✅ New posts: %d\n",
+		summary.Fetched, summary.ItemsSeen, summary.NewPosts)
+	return err
+}
+
+// aggregateOnce fetches each of the named feeds, or every feed not fetched
+// within due when none are named, then prints a summary. It returns an error
+// if any feed failed so that cron and CI jobs can notice.
+func aggregateOnce(s *state, feedRefs []string, due time.Duration) error {
+	ctx := context.Background()
+
+	var feeds []database.Feed
+	if len(feedRefs) > 0 {
+		for _, ref := range feedRefs {
+			feed, err := findFeed(ctx, s, ref)
+			if err != nil {
+				return err
+			}
+			feeds = append(feeds, feed)
+		}
+	} else {
+		var err error
+		feeds, err = s.db.GetFeedsDueForFetch(ctx, sql.NullTime{Time: time.Now().UTC().Add(-due), Valid: true})
+		if err != nil {
+			return fmt.Errorf("error getting feeds to fetch: %w", err)
+		}
+	}
+
+	if len(feeds) == 0 {
+		return printAggSummary(os.Stdout, s.output, aggSummary{})
+	}
+
+	s.logger.Info("Fetching feeds once", logging.Icon("🚀"), "feeds", len(feeds))
+	metrics.SetFeedsDue(len(feeds))
+
+	var failed []string
+	summary := aggSummary{Feeds: len(feeds)}
+	for _, feed := range feeds {
+		result, err := scrapeFeed(ctx, s, feed)
+		if err != nil {
+			s.logger.Error("Could not fetch feed", "feed_id", feed.ID, "feed_url", feed.Url, "error", err)
+			failed = append(failed, feed.Name)
+		}
+		summary.ItemsSeen += result.ItemsSeen
+		summary.NewPosts += result.ItemsInserted
+	}
+	summary.Fetched = len(feeds) - len(failed)
+	summary.Failed = len(failed)
+
+	purgeExpired(ctx, s)
+
+	if err := printAggSummary(os.Stdout, s.output, summary); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		s.logger.Error("Feeds failed", "failed", len(failed), "feeds", strings.Join(failed, ", "))
+		return fmt.Errorf("%d of %d feeds failed to fetch", len(failed), len(feeds))
+	}
+
+	return nil
+}
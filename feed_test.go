@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/config"
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/logging"
+	"github.com/AlexTLDR/gator/internal/memstore"
+
+	"github.com/google/uuid"
+)
+
+const testRSS = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>News</title>
+<item><title>Post one</title><link>https://news.example/1</link><pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate></item>
+<item><title>Post two</title><link>https://news.example/2</link><pubDate>Tue, 03 Jan 2006 15:04:05 GMT</pubDate></item>
+</channel></rss>`
+
+// TestAggregateOnceSummary checks that agg --once prints its summary on
+// stdout in the --output format whatever --log-format is, and never logs it
+func TestAggregateOnceSummary(t *testing.T) {
+	tests := []struct {
+		logFormat string
+		output    outputFormat
+		want      string
+	}{
+		{logging.FormatQuiet, outputText, "📥 Feeds fetched: 1\n📚 Items seen: 2\n✅ New posts: 2\n"},
+		{logging.FormatJSON, outputText, "📥 Feeds fetched: 1\n📚 Items seen: 2\n✅ New posts: 2\n"},
+		{logging.FormatJSON, outputJSON, "[\n  {\n    \"feeds\": 1,\n    \"fetched\": 1,\n    \"failed\": 0,\n    \"items_seen\": 2,\n    \"new_posts\": 2\n  }\n]\n"},
+		{logging.FormatQuiet, outputJSONL, `{"feeds":1,"fetched":1,"failed":0,"items_seen":2,"new_posts":2}` + "\n"},
+		{logging.FormatText, outputCSV, "feeds,fetched,failed,items_seen,new_posts\n1,1,0,2,2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s logs, %s output", tt.logFormat, tt.output), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, testRSS)
+			}))
+			defer srv.Close()
+
+			var logs bytes.Buffer
+			logger, err := logging.New(&logs, tt.logFormat, slog.LevelInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &state{db: memstore.New(), cfg: &config.Config{}, logger: logger, output: tt.output}
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			user, err := s.db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = s.db.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "news", Url: srv.URL, UserID: user.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := captureStdout(t, func() error {
+				return aggregateOnce(s, []string{"news"}, 0)
+			})
+			if out != tt.want {
+				t.Errorf("stdout = %q, want %q", out, tt.want)
+			}
+			if strings.Contains(logs.String(), "Items seen") {
+				t.Errorf("the summary was logged too:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+func TestAggregateOnceNothingDue(t *testing.T) {
+	logger, err := logging.New(&bytes.Buffer{}, logging.FormatQuiet, slog.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &state{db: memstore.New(), cfg: &config.Config{}, logger: logger, output: outputText}
+
+	out := captureStdout(t, func() error {
+		return aggregateOnce(s, nil, 0)
+	})
+	if want := "✅ No feeds are due for fetching\n"; out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// fetchTimeout bounds every HTTP request the aggregator makes
+	fetchTimeout = 10 * time.Second
+	// maxFetchBytes caps the size of any feed or article we download
+	maxFetchBytes = 10 << 20
+	// minHostInterval is the minimum time between two requests to the same host
+	minHostInterval = 2 * time.Second
+)
+
+var httpClient = &http.Client{
+	Timeout: fetchTimeout,
+}
+
+// hostLimiter spaces out requests to the same host so that fetching a feed
+// and then each of its articles doesn't hammer the publisher
+type hostLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+var fetchLimiter = &hostLimiter{next: make(map[string]time.Time)}
+
+// wait blocks until a request to host is allowed or ctx is done
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(minHostInterval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetchResponse is what the server sent back. It is returned even when the
+// fetch fails, so that whatever was received can still be recorded.
+type fetchResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// fetchURL downloads rawURL, applying the shared timeout, per-host rate limit
+// and size cap. Any status other than 200 is an error.
+func fetchURL(ctx context.Context, rawURL string) (fetchResponse, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fetchResponse{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fetchResponse{}, fmt.Errorf("unsupported URL scheme: %q", u.Scheme)
+	}
+
+	if err := fetchLimiter.wait(ctx, u.Host); err != nil {
+		return fetchResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fetchResponse{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "gator")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fetchResponse{}, fmt.Errorf("error fetching %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	result := fetchResponse{StatusCode: resp.StatusCode}
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	result.Body, err = io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return result, fmt.Errorf("error reading response body: %w", err)
+	}
+	if len(result.Body) > maxFetchBytes {
+		return result, fmt.Errorf("response is larger than %d bytes", maxFetchBytes)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchURLSizeCap(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{"at the cap", maxFetchBytes, false},
+		{"over the cap", maxFetchBytes + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.Repeat("x", tt.size)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			resp, err := fetchURL(context.Background(), srv.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchURL error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %d, want 200", resp.StatusCode)
+			}
+			if !tt.wantErr && len(resp.Body) != tt.size {
+				t.Errorf("read %d bytes, want %d", len(resp.Body), tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// benchBatchSize is how many posts go into one INSERT while seeding. Eight
+// parameters a row keeps a batch well under both backends' limits.
+const benchBatchSize = 500
+
+// handlerBench seeds synthetic feeds and posts under a throwaway user,
+// times the queries behind browse and agg against them, and removes the data
+// again. It fails when browse is slower than the target, so it can guard
+// against regressions in CI.
+func handlerBench(s *state, cmd command) error {
+	fs := newFlagSet(cmd)
+	posts := fs.Int("posts", 100000, "synthetic posts to seed")
+	feeds := fs.Int("feeds", 200, "synthetic feeds to spread the posts over")
+	follow := fs.Int("follow", 50, "how many of the feeds the benchmark user follows")
+	runs := fs.Int("runs", 20, "times to run each query")
+	limit := fs.Int("limit", 20, "posts per browse query")
+	target := fs.Duration("target", 200*time.Millisecond, "maximum acceptable p95 latency for browse")
+	keep := fs.Bool("keep", false, "leave the synthetic data in the database")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 0 || *posts < 0 || *feeds < 1 || *follow < 1 || *follow > *feeds || *runs < 1 || *limit < 1 {
+		return fmt.Errorf("usage: %v [--posts <n>] [--feeds <n>] [--follow <n>] [--runs <n>] [--limit <n>] [--target <duration>] [--keep]", cmd.Name)
+	}
+
+	ctx := context.Background()
+
+	fmt.Printf("Seeding %d feeds and %d posts...\n", *feeds, *posts)
+	start := time.Now()
+	user, err := createBenchUser(ctx, s)
+	if err != nil {
+		return err
+	}
+	// Registered before seeding, so that a failed seed is cleaned up too
+	if !*keep {
+		defer func() {
+			fmt.Println("Removing the synthetic data...")
+			if err := removeBenchUser(ctx, s, user); err != nil {
+				fmt.Printf("Couldn't remove user '%s' and its feeds: %v\n", user.Name, err)
+			}
+		}()
+	}
+	if err := seedBench(ctx, s, user, *feeds, *follow, *posts); err != nil {
+		return err
+	}
+	fmt.Printf("Seeded in %v as user '%s'\n\n", time.Since(start).Round(time.Millisecond), user.Name)
+
+	// Refresh the planner's statistics so that it sees the new rows
+	if _, err := s.conn.ExecContext(ctx, `ANALYZE`); err != nil {
+		return fmt.Errorf("couldn't analyze the database: %w", err)
+	}
+
+	browse, err := timeQuery(*runs, func() error {
+		_, err := s.db.GetPostsForUser(ctx, defaultBrowseParams(user.ID, *limit))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("GetPostsForUser: %w", err)
+	}
+	printTimings(fmt.Sprintf("browse (GetPostsForUser, limit %d)", *limit), browse)
+
+	next, err := timeQuery(*runs, func() error {
+		_, err := s.db.GetNextFeedToFetch(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("GetNextFeedToFetch: %w", err)
+	}
+	printTimings("agg (GetNextFeedToFetch)", next)
+
+	fmt.Println()
+	if p95 := percentile(browse, 95); p95 > *target {
+		return fmt.Errorf("browse p95 of %v is over the %v target", p95, *target)
+	}
+	fmt.Printf("browse is within the %v target.\n", *target)
+	return nil
+}
+
+// createBenchUser creates the throwaway user that owns the synthetic feeds
+func createBenchUser(ctx context.Context, s *state) (database.User, error) {
+	now := time.Now().UTC()
+	user, err := s.db.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "bench-" + uuid.NewString()[:8],
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("couldn't create benchmark user: %w", err)
+	}
+	return user, nil
+}
+
+// removeBenchUser deletes user, and with it the synthetic feeds, follows and
+// posts
+func removeBenchUser(ctx context.Context, s *state, user database.User) error {
+	_, err := s.conn.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, user.ID)
+	return err
+}
+
+// seedBench gives user n new feeds and follows the first follow of them,
+// with posts spread evenly over all of them and published a minute apart.
+// The feeds are marked as just fetched, so that agg doesn't try their
+// unreachable URLs ahead of real feeds.
+func seedBench(ctx context.Context, s *state, user database.User, n, follow, posts int) error {
+	now := time.Now().UTC()
+	tag := strings.TrimPrefix(user.Name, "bench-")
+
+	feedIDs := make([]uuid.UUID, n)
+	for i := range feedIDs {
+		feed, err := s.db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      fmt.Sprintf("Bench feed %d", i+1),
+			Url:       fmt.Sprintf("https://bench.invalid/%s/%d/feed.xml", tag, i+1),
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create benchmark feed: %w", err)
+		}
+		feedIDs[i] = feed.ID
+
+		err = s.db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+			LastFetchedAt: sql.NullTime{Time: now, Valid: true},
+			ID:            feed.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't mark benchmark feed fetched: %w", err)
+		}
+
+		if i < follow {
+			_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+				ID:        uuid.New(),
+				CreatedAt: now,
+				UpdatedAt: now,
+				UserID:    user.ID,
+				FeedID:    feed.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("couldn't follow benchmark feed: %w", err)
+			}
+		}
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for first := 0; first < posts; first += benchBatchSize {
+		count := min(benchBatchSize, posts-first)
+		if err := insertBenchPosts(ctx, tx, tag, feedIDs, first, count, now); err != nil {
+			return fmt.Errorf("couldn't insert benchmark posts: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertBenchPosts(ctx context.Context, tx *sql.Tx, tag string, feedIDs []uuid.UUID, first, count int, now time.Time) error {
+	var query strings.Builder
+	query.WriteString(`INSERT INTO posts (id, created_at, updated_at, title, url, published_at, published_at_inferred, feed_id) VALUES `)
+
+	args := make([]interface{}, 0, count*8)
+	for i := 0; i < count; i++ {
+		n := first + i
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		p := len(args)
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", p+1, p+2, p+3, p+4, p+5, p+6, p+7, p+8)
+		args = append(args,
+			uuid.New(),
+			now,
+			now,
+			fmt.Sprintf("Bench post %d", n+1),
+			fmt.Sprintf("https://bench.invalid/%s/posts/%d", tag, n+1),
+			now.Add(-time.Duration(n)*time.Minute),
+			false,
+			feedIDs[n%len(feedIDs)],
+		)
+	}
+
+	_, err := tx.ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+// timeQuery runs query runs times and returns how long each run took,
+// fastest first
+func timeQuery(runs int, query func() error) ([]time.Duration, error) {
+	timings := make([]time.Duration, runs)
+	for i := range timings {
+		start := time.Now()
+		if err := query(); err != nil {
+			return nil, err
+		}
+		timings[i] = time.Since(start)
+	}
+	sort.Slice(timings, func(i, j int) bool { return timings[i] < timings[j] })
+	return timings, nil
+}
+
+// percentile returns the p-th percentile of timings, which must be sorted
+func percentile(timings []time.Duration, p int) time.Duration {
+	i := (len(timings)*p + 99) / 100
+	return timings[max(i-1, 0)]
+}
+
+func printTimings(name string, timings []time.Duration) {
+	round := func(d time.Duration) time.Duration { return d.Round(10 * time.Microsecond) }
+	fmt.Printf("%-40s p50 %-10v p95 %-10v max %v\n",
+		name,
+		round(percentile(timings, 50)),
+		round(percentile(timings, 95)),
+		round(timings[len(timings)-1]),
+	)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// BenchmarkBrowse times the queries behind browse and agg over seeded
+// posts, like 'gator bench' but against a throwaway database: a new SQLite
+// file, and a new schema in GATOR_TEST_DB_URL when that's set.
+func BenchmarkBrowse(b *testing.B) {
+	b.Run("SQLite", func(b *testing.B) {
+		benchmarkBrowse(b, openTestState(b, sqliteTestURL(b)))
+	})
+	b.Run("Postgres", func(b *testing.B) {
+		dbURL := os.Getenv(testDBURLEnv)
+		if dbURL == "" {
+			b.Skipf("set %s to run against Postgres", testDBURLEnv)
+		}
+		benchmarkBrowse(b, openTestState(b, postgresTestURL(b, dbURL)))
+	})
+}
+
+func benchmarkBrowse(b *testing.B, s *state) {
+	ctx := context.Background()
+	user, err := createBenchUser(ctx, s)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := seedBench(ctx, s, user, 200, 50, 100000); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := s.conn.ExecContext(ctx, `ANALYZE`); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("GetPostsForUser", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.db.GetPostsForUser(ctx, defaultBrowseParams(user.ID, 20)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("GetNextFeedToFetch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.db.GetNextFeedToFetch(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
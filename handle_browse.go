@@ -2,20 +2,91 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/htmltext"
+
+	"github.com/google/uuid"
 )
 
+// descriptionLimit is the number of characters of each description that
+// browse shows
+const descriptionLimit = 300
+
+// postRecord is a post as browse prints it with --output. Authors and tags
+// are comma separated, the description and content are plain text, and the
+// cursor is what --before takes to continue after the post.
+type postRecord struct {
+	ID                  uuid.UUID  `json:"id"`
+	Title               string     `json:"title"`
+	URL                 string     `json:"url"`
+	FeedID              uuid.UUID  `json:"feed_id"`
+	FeedName            string     `json:"feed_name"`
+	PublishedAt         *time.Time `json:"published_at"`
+	PublishedAtInferred bool       `json:"published_at_inferred"`
+	FetchedAt           time.Time  `json:"fetched_at"`
+	Authors             string     `json:"authors"`
+	Tags                string     `json:"tags"`
+	Read                bool       `json:"read"`
+	Description         string     `json:"description"`
+	Content             string     `json:"content"`
+	Cursor              string     `json:"cursor"`
+}
+
 func handlerBrowse(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd)
+	tag := fs.String("tag", "", "only show posts with this category")
+	author := fs.String("author", "", "only show posts by this author name or email")
+	feedRef := fs.String("feed", "", "only show posts of this feed (url or name)")
+	since := fs.String("since", "", "only show posts published on or after this date (2006-01-02, today, yesterday) or this long ago (30d, 2w, 12h)")
+	until := fs.String("until", "", "only show posts published on or before this date or this long ago")
+	saved := fs.Bool("saved", false, "only show posts you have saved, read or not")
+	unread := fs.Bool("unread", false, "only show posts you haven't read (the default, except with --saved)")
+	sortBy := fs.String("sort", "published", "order posts by published (newest first), fetched (newest stored first) or feed (by feed name)")
+	format := fs.String("format", "", "render each post with this text/template, or the template of this name in the config")
+	full := fs.Bool("full", false, "show the whole article instead of a summary")
+	all := fs.Bool("all", false, "include posts you have already read")
+	markRead := fs.Bool("mark-read", false, "mark the posts shown as read")
+	page := fs.Int("page", 1, "show this page of limit posts each")
+	before := fs.String("before", "", "show the posts after this cursor, as printed at the end of the previous page")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) > 1 || *page < 1 {
+		return fmt.Errorf("usage: %v [limit] [--feed <url|name>] [--since <date|age>] [--until <date|age>] [--tag <tag>] [--author <author>] [--unread | --all] [--saved] [--sort published|fetched|feed] [--full | --format <template|name>] [--mark-read] [--page <n> | --before <cursor>]", cmd.Name)
+	}
+	if *page > 1 && *before != "" {
+		return fmt.Errorf("use either --page or --before, not both")
+	}
+	if *unread && *all {
+		return fmt.Errorf("use either --unread or --all, not both")
+	}
+	switch *sortBy {
+	case "published", "fetched", "feed":
+	default:
+		return fmt.Errorf("invalid --sort %q: use published, fetched or feed", *sortBy)
+	}
+	if *format != "" && s.output != outputText {
+		return fmt.Errorf("use either --format or --output, not both")
+	}
+	// A cursor names a place in the published order only
+	if *before != "" && *sortBy != "published" {
+		return fmt.Errorf("--before only works with --sort published; use --page instead")
+	}
+
 	// Set default limit
 	limit := 2
 
 	// Check if a limit was provided
-	if len(cmd.Args) > 0 {
-		var err error
-		limit, err = strconv.Atoi(cmd.Args[0])
+	if len(args) > 0 {
+		limit, err = strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid limit: %w", err)
 		}
@@ -24,48 +95,317 @@ func handlerBrowse(s *state, cmd command, user database.User) error {
 		}
 	}
 
+	var tmpl *template.Template
+	if *format != "" {
+		tmpl, err = parseFormat(s.cfg, *format)
+		if err != nil {
+			return fmt.Errorf("invalid --format: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	loc := userLocation(user)
+
+	// Saved posts are usually read already, so --saved shows them all unless
+	// asked otherwise
+	unreadOnly := *unread || (!*all && !*saved)
+
+	params := defaultBrowseParams(user.ID, limit)
+	params.UnreadOnly = unreadOnly
+	params.Tag = sql.NullString{String: normalizeCategory(*tag), Valid: *tag != ""}
+	params.Author = sql.NullString{String: strings.TrimSpace(*author), Valid: *author != ""}
+	params.SavedOnly = *saved
+	params.Sort = *sortBy
+	params.Offset = int32((*page - 1) * limit)
+	if *feedRef != "" {
+		feed, err := findFeed(ctx, s, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if *since != "" {
+		t, err := parseDateFlag(*since, loc, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseDateFlag(*until, loc, true)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+	if *before != "" {
+		params.BeforePublishedAt, params.BeforeID, err = parseCursor(*before)
+		if err != nil {
+			return fmt.Errorf("invalid --before: %w", err)
+		}
+	}
+
 	// Get posts for the user
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-	})
+	posts, err := s.db.GetPostsForUser(ctx, params)
 	if err != nil {
 		return fmt.Errorf("error getting posts: %w", err)
 	}
 
+	if s.output != outputText {
+		records := make([]postRecord, len(posts))
+		for i, post := range posts {
+			records[i] = postRecord{
+				ID:                  post.ID,
+				Title:               post.Title,
+				URL:                 post.Url,
+				FeedID:              post.FeedID,
+				FeedName:            post.FeedName,
+				PublishedAt:         timeField(post.PublishedAt),
+				PublishedAtInferred: post.PublishedAtInferred,
+				FetchedAt:           post.CreatedAt.UTC(),
+				Authors:             post.Authors,
+				Tags:                post.Categories,
+				Read:                post.Read,
+				Description:         htmltext.Render(post.Description.String).Text,
+				Content:             htmltext.Render(post.Content.String).Text,
+				Cursor:              formatCursor(post.PublishedAt, post.ID),
+			}
+		}
+		if *markRead {
+			if err := markBrowsedRead(ctx, s, user, posts); err != nil {
+				return err
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
 	// Check if any posts were found
 	if len(posts) == 0 {
-		fmt.Println("No posts found. Try following some feeds first!")
+		filtered := *tag != "" || *author != "" || *feedRef != "" || *since != "" || *until != "" || *saved
+		switch {
+		case *page > 1 || *before != "":
+			fmt.Println("No more posts.")
+		case filtered && unreadOnly:
+			fmt.Println("No unread posts match. Use --all to include posts you've read.")
+		case filtered:
+			fmt.Println("No posts match.")
+		case unreadOnly:
+			fmt.Println("No unread posts. Use --all to include posts you've read.")
+		default:
+			fmt.Println("No posts found. Try following some feeds first!")
+		}
 		return nil
 	}
 
+	// A template replaces the whole listing, so that it can be as terse as
+	// its author likes
+	if tmpl != nil {
+		if err := printPostsWithTemplate(os.Stdout, tmpl, posts, loc); err != nil {
+			return fmt.Errorf("couldn't render --format: %w", err)
+		}
+		if *markRead {
+			return markBrowsedRead(ctx, s, user, posts)
+		}
+		return nil
+	}
+
+	width := terminalWidth()
+
 	// Display the posts
-	fmt.Printf("Recent posts from feeds you follow (showing %d):\n\n", len(posts))
+	kind := "Recent posts"
+	switch {
+	case *saved && unreadOnly:
+		kind = "Unread saved posts"
+	case *saved:
+		kind = "Saved posts"
+	case unreadOnly:
+		kind = "Unread posts"
+	}
+	fmt.Printf("%s from feeds you follow (showing %d):\n\n", kind, len(posts))
 	for i, post := range posts {
-		fmt.Printf("=== %d ===\n", i+1)
+		if post.Read {
+			fmt.Printf("=== %d (read) ===\n", i+1)
+		} else {
+			fmt.Printf("=== %d ===\n", i+1)
+		}
+		fmt.Printf("ID: %s\n", shortID(post.ID))
 		fmt.Printf("Title: %s\n", post.Title)
 		fmt.Printf("Feed: %s\n", post.FeedName)
+
+		if post.Authors != "" {
+			fmt.Printf("Author: %s\n", post.Authors)
+		}
+
+		if post.Categories != "" {
+			fmt.Printf("Tags: %s\n", post.Categories)
+		}
 		
 		if post.PublishedAt.Valid {
-			fmt.Printf("Published: %v\n", post.PublishedAt.Time.Format("January 2, 2006 15:04:05"))
+			published := post.PublishedAt.Time.In(loc).Format("January 2, 2006 15:04:05 MST")
+			if post.PublishedAtInferred {
+				published += " (estimated, the feed gave no usable date)"
+			}
+			fmt.Printf("Published: %v\n", published)
 		}
 		
 		fmt.Printf("URL: %s\n", post.Url)
 		
-		if post.Description.Valid && post.Description.String != "" {
-			// Display a truncated description if it's too long
-			desc := post.Description.String
-			if len(desc) > 100 {
-				desc = desc[:100] + "..."
+		switch {
+		case *full && post.Content.Valid:
+			printDescription(post.Content.String, width, 0)
+		case post.Description.Valid && post.Description.String != "":
+			limit := descriptionLimit
+			if *full {
+				limit = 0
 			}
-			fmt.Printf("Description: %s\n", desc)
+			printDescription(post.Description.String, width, limit)
 		}
 		
 		fmt.Println()
 	}
 
-	// Show information about browsing more posts
-	fmt.Printf("To view more posts, use: browse <limit>\n")
+	if *markRead {
+		if err := markBrowsedRead(ctx, s, user, posts); err != nil {
+			return err
+		}
+		fmt.Printf("Marked %d posts as read.\n", len(posts))
+	}
+
+	// Show information about browsing more posts. The cursor keeps its place
+	// even when new posts arrive or --mark-read hides the ones shown. A page
+	// number doesn't: once the posts shown are read, the next ones move up
+	// into this page.
+	if len(posts) == limit {
+		nextPage := *page + 1
+		if *markRead && unreadOnly {
+			nextPage = *page
+		}
+		var next []string
+		if nextPage > 1 {
+			next = []string{"--page", strconv.Itoa(nextPage)}
+		}
+		if *sortBy == "published" {
+			last := posts[len(posts)-1]
+			next = []string{"--before", formatCursor(last.PublishedAt, last.ID)}
+		}
+		fmt.Printf("Next page: %s\n", nextPageCommand(cmd, fs, limit, next))
+	}
+	fmt.Printf("To view more posts, use: browse <limit> (filter with --feed, --since, --tag, --author or --saved)\n")
+	fmt.Printf("Mark posts as read with: read <id>, or browse --mark-read\n")
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// defaultBrowseParams returns the query browse runs with no flags: the first
+// limit unread posts of the feeds the user follows, newest first
+func defaultBrowseParams(userID uuid.UUID, limit int) database.GetPostsForUserParams {
+	return database.GetPostsForUserParams{
+		UserID:     userID,
+		UnreadOnly: true,
+		Sort:       "published",
+		Limit:      int32(limit),
+	}
+}
+
+// markBrowsedRead marks the posts browse showed as read
+func markBrowsedRead(ctx context.Context, s *state, user database.User, posts []database.GetPostsForUserRow) error {
+	for _, post := range posts {
+		err := s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't mark '%s' as read: %w", post.Title, err)
+		}
+	}
+	return nil
+}
+
+// printDescription renders an HTML description as wrapped plain text,
+// truncated to limit characters unless limit is 0, followed by the links it
+// references
+func printDescription(description string, width int, limit int) {
+	doc := htmltext.Render(description)
+	if doc.Text == "" {
+		return
+	}
+
+	text := doc.Text
+	if limit > 0 {
+		text = htmltext.Truncate(text, limit)
+	}
+	fmt.Printf("Description:\n%s\n", htmltext.Wrap(text, width))
+
+	for i, link := range doc.Links {
+		marker := fmt.Sprintf("[%d]", i+1)
+		if strings.Contains(text, marker) {
+			fmt.Printf("  %s %s\n", marker, link)
+		}
+	}
+}
+
+// nullCursorDate stands for a missing publication date in a cursor
+const nullCursorDate = "null"
+
+// formatCursor returns a browse cursor for the post published at publishedAt
+// with the given ID, as <published_at>,<id>
+func formatCursor(publishedAt sql.NullTime, id uuid.UUID) string {
+	date := nullCursorDate
+	if publishedAt.Valid {
+		date = publishedAt.Time.UTC().Format(time.RFC3339Nano)
+	}
+	return date + "," + id.String()
+}
+
+// parseCursor parses a cursor made by formatCursor
+func parseCursor(cursor string) (sql.NullTime, uuid.NullUUID, error) {
+	date, idText, ok := strings.Cut(cursor, ",")
+	if !ok {
+		return sql.NullTime{}, uuid.NullUUID{}, fmt.Errorf("%q is not <published_at>,<id>", cursor)
+	}
+
+	id, err := uuid.Parse(idText)
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, fmt.Errorf("%q has no valid post ID: %w", cursor, err)
+	}
+
+	var publishedAt sql.NullTime
+	if date != nullCursorDate {
+		t, err := time.Parse(time.RFC3339Nano, date)
+		if err != nil {
+			return sql.NullTime{}, uuid.NullUUID{}, fmt.Errorf("%q has no valid date: %w", cursor, err)
+		}
+		publishedAt = sql.NullTime{Time: t.UTC(), Valid: true}
+	}
+
+	return publishedAt, uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+// nextPageCommand spells out the browse command for the next page, given
+// the flags that select it, keeping the filters of the current one
+func nextPageCommand(cmd command, fs *flag.FlagSet, limit int, next []string) string {
+	parts := []string{cmd.Name, strconv.Itoa(limit)}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "page" || f.Name == "before" {
+			return
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			if f.Value.String() == "true" {
+				parts = append(parts, "--"+f.Name)
+			}
+			return
+		}
+		parts = append(parts, "--"+f.Name, shellQuote(f.Value.String()))
+	})
+	parts = append(parts, next...)
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if it needs it
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.,:/@+=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
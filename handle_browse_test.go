@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("0192f3a4-5b6c-7d8e-9f01-23456789abcd")
+	berlin := time.FixedZone("CEST", 2*60*60)
+
+	tests := []struct {
+		name        string
+		publishedAt sql.NullTime
+	}{
+		{"no date", sql.NullTime{}},
+		{"whole seconds", sql.NullTime{Time: time.Date(2026, 9, 1, 8, 30, 0, 0, time.UTC), Valid: true}},
+		{"microseconds, as Postgres stores", sql.NullTime{Time: time.Date(2026, 9, 1, 8, 30, 0, 123456000, time.UTC), Valid: true}},
+		{"nanoseconds", sql.NullTime{Time: time.Date(2026, 9, 1, 8, 30, 0, 1, time.UTC), Valid: true}},
+		{"another zone", sql.NullTime{Time: time.Date(2026, 9, 1, 10, 30, 0, 0, berlin), Valid: true}},
+		{"zero time", sql.NullTime{Time: time.Time{}, Valid: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := formatCursor(tt.publishedAt, id)
+			gotDate, gotID, err := parseCursor(cursor)
+			if err != nil {
+				t.Fatalf("parseCursor(%q) failed: %v", cursor, err)
+			}
+			if gotID != (uuid.NullUUID{UUID: id, Valid: true}) {
+				t.Errorf("parseCursor(%q) ID = %v, want %v", cursor, gotID, id)
+			}
+			if gotDate.Valid != tt.publishedAt.Valid || !gotDate.Time.Equal(tt.publishedAt.Time) {
+				t.Errorf("parseCursor(%q) date = %v, want %v", cursor, gotDate, tt.publishedAt)
+			}
+			if gotDate.Valid && gotDate.Time.Location() != time.UTC {
+				t.Errorf("parseCursor(%q) date is in %v, want UTC", cursor, gotDate.Time.Location())
+			}
+			if again := formatCursor(gotDate, gotID.UUID); again != cursor {
+				t.Errorf("formatCursor after parseCursor = %q, want %q", again, cursor)
+			}
+		})
+	}
+}
+
+func TestParseCursorErrors(t *testing.T) {
+	for _, cursor := range []string{
+		"",
+		"null",
+		"0192f3a4-5b6c-7d8e-9f01-23456789abcd",
+		"null,",
+		"null,not-an-id",
+		"yesterday,0192f3a4-5b6c-7d8e-9f01-23456789abcd",
+		"2026-09-01,0192f3a4-5b6c-7d8e-9f01-23456789abcd",
+		"NULL,0192f3a4-5b6c-7d8e-9f01-23456789abcd",
+		"2026-09-01T08:30:00Z,0192f3a4,5b6c",
+	} {
+		if date, id, err := parseCursor(cursor); err == nil {
+			t.Errorf("parseCursor(%q) = %v, %v; want an error", cursor, date, id)
+		}
+	}
+}
+
+// TestCursorPaging pages through posts by the text of their cursors, so
+// that a date losing precision on its way through a cursor would skip or
+// repeat posts
+func TestCursorPaging(t *testing.T) {
+	s := openTestState(t, sqliteTestURL(t))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	user, err := s.db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := s.db.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "news", Url: "https://news.example/feed.xml", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: user.ID, FeedID: feed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Undated posts, and dates shared by several posts a microsecond apart
+	dates := []sql.NullTime{{}, {}}
+	for i := 0; i < 3; i++ {
+		date := sql.NullTime{Time: now.Add(-time.Duration(i) * time.Microsecond).Truncate(time.Microsecond), Valid: true}
+		dates = append(dates, date, date, date)
+	}
+	for i, date := range dates {
+		_, err := s.db.CreatePost(ctx, database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Title:       fmt.Sprintf("Post %d", i),
+			Url:         fmt.Sprintf("https://news.example/%d", i),
+			PublishedAt: date,
+			FeedID:      feed.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	params := database.GetPostsForUserParams{UserID: user.ID, Sort: "published", Limit: 2}
+	for len(seen) < len(dates) {
+		posts, err := s.db.GetPostsForUser(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+		for _, p := range posts {
+			if seen[p.ID] {
+				t.Fatalf("%s came up twice", p.Title)
+			}
+			seen[p.ID] = true
+		}
+
+		last := posts[len(posts)-1]
+		params.BeforePublishedAt, params.BeforeID, err = parseCursor(formatCursor(last.PublishedAt, last.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(seen) != len(dates) {
+		t.Errorf("paging by cursor showed %d of %d posts", len(seen), len(dates))
+	}
+}
+
+// TestBrowseNextPageHint follows the next page hints browse prints, which
+// must show every post once whether or not --mark-read takes the ones shown
+// out of the listing
+func TestBrowseNextPageHint(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"by page", []string{"1", "--sort", "feed"}},
+		{"by page, marking read", []string{"1", "--sort", "feed", "--mark-read"}},
+		{"by page from page 2, marking read", []string{"1", "--sort", "fetched", "--mark-read", "--page", "2"}},
+		{"by cursor, marking read", []string{"1", "--mark-read"}},
+		{"by page, marking read with --all", []string{"1", "--sort", "feed", "--mark-read", "--all"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestState(t, sqliteTestURL(t))
+			s.output = outputText
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+
+			user, err := s.db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			feed, err := s.db.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "news", Url: "https://news.example/feed.xml", UserID: user.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: user.ID, FeedID: feed.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			titles := []string{"Post one", "Post two", "Post three", "Post four"}
+			for i, title := range titles {
+				at := now.Add(-time.Duration(i) * time.Hour)
+				_, err := s.db.CreatePost(ctx, database.CreatePostParams{
+					ID:          uuid.New(),
+					CreatedAt:   at,
+					UpdatedAt:   at,
+					Title:       title,
+					Url:         fmt.Sprintf("https://news.example/%d", i),
+					PublishedAt: sql.NullTime{Time: at, Valid: true},
+					FeedID:      feed.ID,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Starting on page 2 skips the first post
+			want := titles
+			if slices.Contains(tt.args, "--page") {
+				want = titles[1:]
+			}
+
+			var shown []string
+			args := tt.args
+			for i := 0; args != nil; i++ {
+				if i > len(titles) {
+					t.Fatalf("still paging after %d pages, showing %v", i, shown)
+				}
+				out := captureStdout(t, func() error {
+					return handlerBrowse(s, command{Name: "browse", Args: args}, user)
+				})
+
+				for _, title := range titles {
+					if strings.Contains(out, "Title: "+title+"\n") {
+						shown = append(shown, title)
+					}
+				}
+
+				args = nil
+				for _, line := range strings.Split(out, "\n") {
+					if hint, ok := strings.CutPrefix(line, "Next page: browse "); ok {
+						args = strings.Fields(hint)
+					}
+				}
+			}
+
+			if !slices.Equal(shown, want) {
+				t.Errorf("following the hints of browse %s showed %v, want %v", strings.Join(tt.args, " "), shown, want)
+			}
+		})
+	}
+}
+
+// captureStdout returns what fn prints
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	err = fn()
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return <-out
+}
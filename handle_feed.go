@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/AlexTLDR/gator/internal/database"
@@ -64,6 +66,24 @@ func printFeed(feed database.Feed) {
 	} else {
 		fmt.Printf(" * Fetched:   Never\n")
 	}
+	fmt.Printf(" * Full text: %v\n", onOff(feed.FetchFullArticle))
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// feedRecord is a feed as feeds prints it with --output
+type feedRecord struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	AddedBy   string    `json:"added_by"`
+	FullText  bool      `json:"full_text"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func handlerFeeds(s *state, cmd command) error {
@@ -78,6 +98,21 @@ func handlerFeeds(s *state, cmd command) error {
 		return fmt.Errorf("couldn't retrieve feeds: %w", err)
 	}
 
+	if s.output != outputText {
+		records := make([]feedRecord, len(feeds))
+		for i, feed := range feeds {
+			records[i] = feedRecord{
+				ID:        feed.ID,
+				Name:      feed.Name,
+				URL:       feed.Url,
+				AddedBy:   feed.UserName,
+				FullText:  feed.FetchFullArticle,
+				CreatedAt: feed.CreatedAt.UTC(),
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
 	if len(feeds) == 0 {
 		fmt.Println("No feeds found in the database.")
 		return nil
@@ -87,8 +122,70 @@ func handlerFeeds(s *state, cmd command) error {
 	for i, feed := range feeds {
 		fmt.Printf("%d. %s\n", i+1, feed.Name)
 		fmt.Printf("   URL:  %s\n", feed.Url)
+		if feed.FetchFullArticle {
+			fmt.Printf("   Full text: on\n")
+		}
 		fmt.Printf("   User: %s\n\n", feed.UserName)
 	}
 
 	return nil
 }
+
+func handlerFullText(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 2 || (cmd.Args[1] != "on" && cmd.Args[1] != "off") {
+		return fmt.Errorf("usage: %v <url> <on|off>", cmd.Name)
+	}
+
+	url := cmd.Args[0]
+	enabled := cmd.Args[1] == "on"
+
+	feed, err := s.db.GetFeedByURL(context.Background(), url)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL '%s': %w", url, err)
+	}
+
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who added '%s' can change its full text mode", feed.Name)
+	}
+
+	err = s.db.SetFeedFetchFullArticle(context.Background(), database.SetFeedFetchFullArticleParams{
+		FetchFullArticle: enabled,
+		UpdatedAt:        time.Now().UTC(),
+		ID:               feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update feed: %w", err)
+	}
+
+	if enabled {
+		fmt.Printf("Full articles will be downloaded for new posts from '%s'\n", feed.Name)
+	} else {
+		fmt.Printf("Full articles will no longer be downloaded for '%s'\n", feed.Name)
+	}
+	return nil
+}
+
+// findFeed looks a feed up by URL, falling back to its name
+func findFeed(ctx context.Context, s *state, ref string) (database.Feed, error) {
+	feed, err := s.db.GetFeedByURL(ctx, ref)
+	if err == nil {
+		return feed, nil
+	}
+	if err != sql.ErrNoRows {
+		return database.Feed{}, fmt.Errorf("couldn't look up feed '%s': %w", ref, err)
+	}
+
+	feeds, err := s.db.GetFeedsByName(ctx, ref)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("couldn't look up feed '%s': %w", ref, err)
+	}
+
+	switch len(feeds) {
+	case 0:
+		return database.Feed{}, fmt.Errorf("couldn't find a feed with URL or name '%s'", ref)
+	case 1:
+		return feeds[0], nil
+	default:
+		return database.Feed{}, fmt.Errorf("%d feeds are named '%s', use the feed URL instead", len(feeds), ref)
+	}
+}
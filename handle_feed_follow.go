@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/AlexTLDR/gator/internal/database"
@@ -59,6 +60,15 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
+// followRecord is a followed feed as following prints it with --output
+type followRecord struct {
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	FeedURL     string    `json:"feed_url"`
+	FollowedAt  time.Time `json:"followed_at"`
+	UnreadCount int64     `json:"unread_count"`
+}
+
 func handlerFollowing(s *state, cmd command, user database.User) error {
 	// Check for correct number of arguments
 	if len(cmd.Args) != 0 {
@@ -71,16 +81,32 @@ func handlerFollowing(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("couldn't get feed follows: %w", err)
 	}
 
+	if s.output != outputText {
+		records := make([]followRecord, len(feedFollows))
+		for i, ff := range feedFollows {
+			records[i] = followRecord{
+				FeedID:      ff.FeedID,
+				FeedName:    ff.FeedName,
+				FeedURL:     ff.FeedUrl,
+				FollowedAt:  ff.CreatedAt.UTC(),
+				UnreadCount: ff.UnreadCount,
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
 	if len(feedFollows) == 0 {
 		fmt.Printf("User '%s' is not following any feeds.\n", user.Name)
 		return nil
 	}
 
+	loc := userLocation(user)
 	fmt.Printf("Feeds followed by '%s':\n", user.Name)
 	fmt.Println("-----------------------------")
 	for i, ff := range feedFollows {
 		fmt.Printf("%d. %s\n", i+1, ff.FeedName)
-		fmt.Printf("   Started following: %s\n", ff.CreatedAt.Format("Jan 02, 2006 15:04:05"))
+		fmt.Printf("   Started following: %s\n", ff.CreatedAt.In(loc).Format("Jan 02, 2006 15:04:05 MST"))
+		fmt.Printf("   Unread posts: %d\n", ff.UnreadCount)
 	}
 	fmt.Printf("\nTotal feeds followed: %d\n", len(feedFollows))
 
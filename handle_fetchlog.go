@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// fetchRecord is a fetch as fetchlog prints it with --output. HTTPStatus is
+// null when the server never answered, and FinishedAt while it's running.
+type fetchRecord struct {
+	ID            uuid.UUID  `json:"id"`
+	FeedID        uuid.UUID  `json:"feed_id"`
+	FeedName      string     `json:"feed_name"`
+	FeedURL       string     `json:"feed_url"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	HTTPStatus    *int32     `json:"http_status"`
+	Bytes         int64      `json:"bytes"`
+	ItemsSeen     int32      `json:"items_seen"`
+	ItemsInserted int32      `json:"items_inserted"`
+	Error         *string    `json:"error"`
+}
+
+func handlerFetchLog(s *state, cmd command) error {
+	fs := newFlagSet(cmd)
+	limit := fs.Int("limit", 20, "number of fetches to show")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) > 1 || *limit < 1 {
+		return fmt.Errorf("usage: %v [feed url|name] [--limit <n>]", cmd.Name)
+	}
+
+	ctx := context.Background()
+
+	var feedID uuid.NullUUID
+	title := "Recent fetches"
+	if len(args) == 1 {
+		feed, err := findFeed(ctx, s, args[0])
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		title = fmt.Sprintf("Recent fetches of '%s'", feed.Name)
+	}
+
+	fetches, err := s.db.GetFeedFetches(ctx, database.GetFeedFetchesParams{
+		FeedID: feedID,
+		Limit:  int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get fetch history: %w", err)
+	}
+
+	if s.output != outputText {
+		records := make([]fetchRecord, len(fetches))
+		for i, f := range fetches {
+			records[i] = fetchRecord{
+				ID:            f.ID,
+				FeedID:        f.FeedID,
+				FeedName:      f.FeedName,
+				FeedURL:       f.FeedUrl,
+				StartedAt:     f.StartedAt.UTC(),
+				FinishedAt:    timeField(f.FinishedAt),
+				Bytes:         f.Bytes,
+				ItemsSeen:     f.ItemsSeen,
+				ItemsInserted: f.ItemsInserted,
+				Error:         stringField(f.Error),
+			}
+			if f.HttpStatus.Valid {
+				records[i].HTTPStatus = &f.HttpStatus.Int32
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
+	if len(fetches) == 0 {
+		fmt.Println("No fetches recorded yet. Run 'agg' to fetch feeds.")
+		return nil
+	}
+
+	loc := currentUserLocation(ctx, s)
+	fmt.Printf("%s (showing %d):\n\n", title, len(fetches))
+	for _, f := range fetches {
+		printFeedFetch(f, !feedID.Valid, loc)
+	}
+
+	return nil
+}
+
+func printFeedFetch(f database.GetFeedFetchesRow, showFeed bool, loc *time.Location) {
+	status := "-"
+	if f.HttpStatus.Valid {
+		status = strconv.Itoa(int(f.HttpStatus.Int32))
+	}
+
+	duration := "running"
+	if f.FinishedAt.Valid {
+		duration = f.FinishedAt.Time.Sub(f.StartedAt).Round(time.Millisecond).String()
+	}
+
+	fmt.Printf("%s  status %-3s  %8s  %3d items, %3d new  %s\n",
+		f.StartedAt.In(loc).Format("2006-01-02 15:04:05 MST"),
+		status,
+		formatBytes(f.Bytes),
+		f.ItemsSeen,
+		f.ItemsInserted,
+		duration,
+	)
+	if showFeed {
+		fmt.Printf("   Feed:  %s (%s)\n", f.FeedName, f.FeedUrl)
+	}
+	if f.Error.Valid {
+		fmt.Printf("   Error: %s\n", f.Error.String)
+	}
+}
+
+// formatBytes renders a byte count in a human readable unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
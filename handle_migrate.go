@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/AlexTLDR/gator/internal/migrate"
+)
+
+func handlerMigrate(s *state, cmd command) error {
+	usage := fmt.Errorf("usage: %v status | up [n] [--dry-run] | down [n] [--dry-run]", cmd.Name)
+
+	fs := newFlagSet(cmd)
+	dryRun := fs.Bool("dry-run", false, "print the SQL instead of running it")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) == 0 || len(args) > 2 {
+		return usage
+	}
+
+	n := 0
+	switch args[0] {
+	case "status":
+		if len(args) != 1 || *dryRun {
+			return usage
+		}
+	case "up", "down":
+		if args[0] == "down" {
+			n = 1
+		}
+		if len(args) == 2 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return usage
+			}
+		}
+	default:
+		return usage
+	}
+
+	ctx := context.Background()
+
+	// Looking must not touch the database, not even to set up
+	// schema_migrations, so only a real up or down takes the lock
+	var m *migrate.Migrator
+	if args[0] == "status" || *dryRun {
+		if m, err = previewMigrator(ctx, s.conn, s.dialect); err != nil {
+			return err
+		}
+	} else {
+		var unlock func()
+		if m, unlock, err = openMigrator(ctx, s.conn, s.dialect); err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(ctx, m)
+	case "up":
+		return migrateUp(ctx, m, n, *dryRun)
+	default:
+		return migrateDown(ctx, m, n, *dryRun)
+	}
+}
+
+func printMigrationStatus(ctx context.Context, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, st := range statuses {
+		when := "pending"
+		if st.Applied && st.AppliedAt.IsZero() {
+			// An unversioned database that 'migrate up' will baseline
+			when = "applied (not yet recorded)"
+		} else if st.Applied {
+			when = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+
+		note := ""
+		switch {
+		case st.Unknown:
+			note = "  (not known to this version of gator)"
+		case st.Changed:
+			note = "  (changed since it was applied)"
+		}
+		fmt.Printf("%-40s %s%s\n", st.Name, when, note)
+	}
+
+	fmt.Println()
+	if pending == 0 {
+		fmt.Println("The schema is up to date.")
+	} else {
+		fmt.Printf("%d migration(s) pending. Run 'migrate up' to apply them.\n", pending)
+	}
+	return nil
+}
+
+func migrateUp(ctx context.Context, m *migrate.Migrator, n int, dryRun bool) error {
+	if dryRun {
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 && n < len(pending) {
+			pending = pending[:n]
+		}
+		if len(pending) == 0 {
+			fmt.Println("Nothing to apply, the schema is up to date.")
+		}
+		for _, mig := range pending {
+			fmt.Printf("-- %s (up)\n%s\n\n", mig.Name, mig.Up)
+		}
+		return nil
+	}
+
+	applied := 0
+	err := m.Up(ctx, n, func(mig migrate.Migration) {
+		fmt.Printf("Applying %s...\n", mig.Name)
+		applied++
+	})
+	if err != nil {
+		return err
+	}
+
+	if applied == 0 {
+		fmt.Println("Nothing to apply, the schema is up to date.")
+	} else {
+		fmt.Printf("Applied %d migration(s).\n", applied)
+	}
+	return nil
+}
+
+func migrateDown(ctx context.Context, m *migrate.Migrator, n int, dryRun bool) error {
+	if dryRun {
+		revert, err := m.Reversible(ctx, n)
+		if err != nil {
+			return err
+		}
+		if len(revert) == 0 {
+			fmt.Println("Nothing to revert, no migrations have been applied.")
+		}
+		for _, mig := range revert {
+			fmt.Printf("-- %s (down)\n%s\n\n", mig.Name, mig.Down)
+		}
+		return nil
+	}
+
+	reverted := 0
+	err := m.Down(ctx, n, func(mig migrate.Migration) {
+		fmt.Printf("Reverting %s...\n", mig.Name)
+		reverted++
+	})
+	if err != nil {
+		return err
+	}
+
+	if reverted == 0 {
+		fmt.Println("Nothing to revert, no migrations have been applied.")
+	} else {
+		fmt.Printf("Reverted %d migration(s).\n", reverted)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// shortIDLength is how much of a post's ID browse shows. Any prefix that
+// picks out a single post works as a reference, so this only needs to be
+// long enough to be unique in practice.
+const shortIDLength = 8
+
+func handlerRead(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %v <post id|url>...", cmd.Name)
+	}
+
+	ctx := context.Background()
+	for _, ref := range cmd.Args {
+		post, err := findPost(ctx, s, user, ref)
+		if err != nil {
+			return err
+		}
+
+		err = s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't mark '%s' as read: %w", post.Title, err)
+		}
+		fmt.Printf("Marked '%s' as read\n", post.Title)
+	}
+	return nil
+}
+
+func handlerUnread(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %v <post id|url>...", cmd.Name)
+	}
+
+	ctx := context.Background()
+	for _, ref := range cmd.Args {
+		post, err := findPost(ctx, s, user, ref)
+		if err != nil {
+			return err
+		}
+
+		err = s.db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't mark '%s' as unread: %w", post.Title, err)
+		}
+		fmt.Printf("Marked '%s' as unread\n", post.Title)
+	}
+	return nil
+}
+
+func handlerMarkRead(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd)
+	feedRef := fs.String("feed", "", "only mark posts of this feed (url or name)")
+	before := fs.String("before", "", "only mark posts published before this date (2006-01-02) or more than this long ago (30d, 2w, 12h)")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 0 {
+		return fmt.Errorf("usage: %v [--feed <url|name>] [--before <date|age>]", cmd.Name)
+	}
+
+	ctx := context.Background()
+
+	params := database.MarkPostsReadParams{
+		ReadAt: time.Now().UTC(),
+		UserID: user.ID,
+	}
+	if *feedRef != "" {
+		feed, err := findFeed(ctx, s, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if *before != "" {
+		t, err := parseDateFlag(*before, userLocation(user), false)
+		if err != nil {
+			return fmt.Errorf("invalid --before: %w", err)
+		}
+		params.Before = sql.NullTime{Time: t, Valid: true}
+	}
+
+	n, err := s.db.MarkPostsRead(ctx, params)
+	if err != nil {
+		return fmt.Errorf("couldn't mark posts as read: %w", err)
+	}
+
+	fmt.Printf("Marked %d posts as read\n", n)
+	return nil
+}
+
+// findPost looks a post of a feed user follows up by URL, falling back to
+// its ID or the start of it as shown by browse
+func findPost(ctx context.Context, s *state, user database.User, ref string) (database.GetPostByURLRow, error) {
+	notFound := fmt.Errorf("couldn't find a post with URL or ID '%s' in the feeds you follow", ref)
+
+	post, err := s.db.GetPostByURL(ctx, ref)
+	if err == nil {
+		_, err = s.db.GetFeedFollowByUserAndFeed(ctx, database.GetFeedFollowByUserAndFeedParams{UserID: user.ID, FeedID: post.FeedID})
+		if err == sql.ErrNoRows {
+			return database.GetPostByURLRow{}, notFound
+		}
+		if err != nil {
+			return database.GetPostByURLRow{}, fmt.Errorf("couldn't look up post '%s': %w", ref, err)
+		}
+		return post, nil
+	}
+	if err != sql.ErrNoRows {
+		return database.GetPostByURLRow{}, fmt.Errorf("couldn't look up post '%s': %w", ref, err)
+	}
+
+	lower, upper, ok := idPrefixRange(ref)
+	if !ok {
+		return database.GetPostByURLRow{}, notFound
+	}
+
+	posts, err := s.db.GetPostsByIDPrefix(ctx, database.GetPostsByIDPrefixParams{
+		UserID: user.ID,
+		Lower:  lower,
+		Upper:  upper,
+	})
+	if err != nil {
+		return database.GetPostByURLRow{}, fmt.Errorf("couldn't look up post '%s': %w", ref, err)
+	}
+
+	switch len(posts) {
+	case 0:
+		return database.GetPostByURLRow{}, notFound
+	case 1:
+		return database.GetPostByURLRow(posts[0]), nil
+	default:
+		return database.GetPostByURLRow{}, fmt.Errorf("more than one post has an ID starting with '%s', give more of it", ref)
+	}
+}
+
+// idPrefixRange returns the smallest and largest IDs that start with
+// prefix, as written in the usual 8-4-4-4-12 form with or without its
+// dashes. It reports false if no ID can start with prefix.
+func idPrefixRange(prefix string) (lower, upper uuid.UUID, ok bool) {
+	prefix = strings.ToLower(prefix)
+	if prefix == "" || strings.Trim(prefix, "0123456789abcdef-") != "" {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if strings.Contains(prefix, "-") {
+		// The dashes have to be where the full ID has them
+		const layout = "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+		if len(prefix) > len(layout) {
+			return uuid.Nil, uuid.Nil, false
+		}
+		for i := range prefix {
+			if (prefix[i] == '-') != (layout[i] == '-') {
+				return uuid.Nil, uuid.Nil, false
+			}
+		}
+	}
+	hex := strings.ReplaceAll(prefix, "-", "")
+	if len(hex) > 32 {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	lower, err := uuid.Parse(hex + strings.Repeat("0", 32-len(hex)))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	upper, err = uuid.Parse(hex + strings.Repeat("f", 32-len(hex)))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return lower, upper, true
+}
+
+// shortID is the start of a post's ID that browse shows
+func shortID(id uuid.UUID) string {
+	return id.String()[:shortIDLength]
+}
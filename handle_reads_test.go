@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestIDPrefixRange(t *testing.T) {
+	tests := []struct {
+		prefix       string
+		lower, upper string
+	}{
+		{"a", "a0000000-0000-0000-0000-000000000000", "afffffff-ffff-ffff-ffff-ffffffffffff"},
+		{"ABCD1234", "abcd1234-0000-0000-0000-000000000000", "abcd1234-ffff-ffff-ffff-ffffffffffff"},
+		{"abcd1234-", "abcd1234-0000-0000-0000-000000000000", "abcd1234-ffff-ffff-ffff-ffffffffffff"},
+		{"abcd1234-5", "abcd1234-5000-0000-0000-000000000000", "abcd1234-5fff-ffff-ffff-ffffffffffff"},
+		{"abcd12345", "abcd1234-5000-0000-0000-000000000000", "abcd1234-5fff-ffff-ffff-ffffffffffff"},
+		{"abcd1234-5678-4abc-8def-0123456789ab", "abcd1234-5678-4abc-8def-0123456789ab", "abcd1234-5678-4abc-8def-0123456789ab"},
+		{"abcd123456784abc8def0123456789ab", "abcd1234-5678-4abc-8def-0123456789ab", "abcd1234-5678-4abc-8def-0123456789ab"},
+	}
+	for _, tt := range tests {
+		lower, upper, ok := idPrefixRange(tt.prefix)
+		if !ok || lower != uuid.MustParse(tt.lower) || upper != uuid.MustParse(tt.upper) {
+			t.Errorf("idPrefixRange(%q) = %v, %v, %v; want %s, %s", tt.prefix, lower, upper, ok, tt.lower, tt.upper)
+		}
+	}
+
+	for _, prefix := range []string{
+		"",
+		"-",
+		"abcg",
+		"abcd-1234",
+		"abcd1234--",
+		"abcd1234-5678-4abc-8def-0123456789abc",
+		"abcd123456784abc8def0123456789abc",
+		"https://example.com/post",
+		"abc%",
+	} {
+		if lower, upper, ok := idPrefixRange(prefix); ok {
+			t.Errorf("idPrefixRange(%q) = %v, %v; want no range", prefix, lower, upper)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+)
+
+func handlerPurge(s *state, cmd command) error {
+	fs := newFlagSet(cmd)
+	dryRun := fs.Bool("dry-run", false, "report what would be deleted without deleting it")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("usage: %v [--dry-run] [feed url|name...]", cmd.Name)
+	}
+
+	ctx := context.Background()
+
+	var feeds []database.Feed
+	if len(args) > 0 {
+		for _, ref := range args {
+			feed, err := findFeed(ctx, s, ref)
+			if err != nil {
+				return err
+			}
+			feeds = append(feeds, feed)
+		}
+	} else {
+		feeds, err = s.db.GetAllFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't list feeds: %w", err)
+		}
+	}
+
+	results, err := purgeFeeds(ctx, s, feeds, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No retention limits apply; set retention_days or retention_keep in the config, or use 'gator retention'.")
+		return nil
+	}
+
+	verb := "Deleted"
+	if *dryRun {
+		verb = "Would delete"
+	}
+
+	var total int64
+	var touched int
+	for _, r := range results {
+		if r.Posts == 0 {
+			continue
+		}
+		fmt.Printf(" * %s: %d posts (keeping %s)\n", r.Feed.Name, r.Posts, r.Policy)
+		total += r.Posts
+		touched++
+	}
+
+	if total == 0 {
+		fmt.Println("Nothing to delete.")
+		return nil
+	}
+	fmt.Printf("%s %d posts from %d feeds.\n", verb, total, touched)
+	return nil
+}
+
+func handlerRetention(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd)
+	days := fs.Int("days", 0, "keep posts published within this many days, 0 for no age limit")
+	keep := fs.Int("keep", 0, "keep this many of the newest posts, 0 for no count limit")
+	reset := fs.Bool("default", false, "go back to the limits in the config")
+
+	usage := fmt.Errorf("usage: %v <feed url|name> [--days <n>] [--keep <n>] | %v <feed url|name> --default", cmd.Name, cmd.Name)
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 1 || *days < 0 || *keep < 0 {
+		return usage
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if *reset && (set["days"] || set["keep"]) {
+		return usage
+	}
+
+	ctx := context.Background()
+
+	feed, err := findFeed(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(set) == 0 {
+		fmt.Printf("Keeping %s of '%s'%s.\n", feedRetention(s, feed), feed.Name, retentionSource(feed))
+		return nil
+	}
+
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who added '%s' can change its retention", feed.Name)
+	}
+
+	params := database.SetFeedRetentionParams{
+		RetentionDays: feed.RetentionDays,
+		RetentionKeep: feed.RetentionKeep,
+		UpdatedAt:     time.Now().UTC(),
+		ID:            feed.ID,
+	}
+	if *reset {
+		params.RetentionDays = sql.NullInt32{}
+		params.RetentionKeep = sql.NullInt32{}
+	}
+	if set["days"] {
+		params.RetentionDays = sql.NullInt32{Int32: int32(*days), Valid: true}
+	}
+	if set["keep"] {
+		params.RetentionKeep = sql.NullInt32{Int32: int32(*keep), Valid: true}
+	}
+
+	if err := s.db.SetFeedRetention(ctx, params); err != nil {
+		return fmt.Errorf("couldn't update feed: %w", err)
+	}
+
+	feed.RetentionDays = params.RetentionDays
+	feed.RetentionKeep = params.RetentionKeep
+	fmt.Printf("Will keep %s of '%s'%s.\n", feedRetention(s, feed), feed.Name, retentionSource(feed))
+	return nil
+}
+
+// retentionSource explains where a feed's retention limits come from
+func retentionSource(feed database.Feed) string {
+	switch {
+	case feed.RetentionDays.Valid && feed.RetentionKeep.Valid:
+		return ""
+	case feed.RetentionDays.Valid || feed.RetentionKeep.Valid:
+		return ", partly from the config"
+	default:
+		return ", as set in the config"
+	}
+}
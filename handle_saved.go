@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func handlerSave(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd)
+	note := fs.String("note", "", "a note to keep with the post, replacing any earlier one")
+	var tags, untags stringList
+	fs.Var(&tags, "tag", "tag the saved post (can be repeated)")
+	fs.Var(&untags, "untag", "remove a tag from the saved post (can be repeated)")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %v <post id|url> [--note <text>] [--tag <tag>]... [--untag <tag>]...", cmd.Name)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	ctx := context.Background()
+
+	// Look among the saves first, as their post may have been deleted since
+	saved, found, err := findSavedPost(ctx, s, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		post, err := findPost(ctx, s, user, args[0])
+		if err != nil {
+			return err
+		}
+		saved, err = s.db.CreateSavedPost(ctx, database.CreateSavedPostParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			CreatedAt: time.Now().UTC(),
+			Note:      strings.TrimSpace(*note),
+			PostID:    post.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't save '%s': %w", post.Title, err)
+		}
+		fmt.Printf("Saved '%s'\n", saved.Title)
+	} else if set["note"] {
+		err := s.db.SetSavedPostNote(ctx, database.SetSavedPostNoteParams{
+			Note:      strings.TrimSpace(*note),
+			UpdatedAt: time.Now().UTC(),
+			ID:        saved.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't update the note of '%s': %w", saved.Title, err)
+		}
+		fmt.Printf("Updated the note of '%s'\n", saved.Title)
+	} else if len(tags) == 0 && len(untags) == 0 {
+		fmt.Printf("'%s' is already saved\n", saved.Title)
+	}
+
+	for _, tag := range tags {
+		tag = normalizeCategory(tag)
+		if tag == "" {
+			continue
+		}
+		err := s.db.AddSavedPostTag(ctx, database.AddSavedPostTagParams{SavedPostID: saved.ID, Tag: tag})
+		if err != nil {
+			return fmt.Errorf("couldn't tag '%s': %w", saved.Title, err)
+		}
+		fmt.Printf("Tagged '%s' with %q\n", saved.Title, tag)
+	}
+
+	for _, tag := range untags {
+		tag = normalizeCategory(tag)
+		err := s.db.RemoveSavedPostTag(ctx, database.RemoveSavedPostTagParams{SavedPostID: saved.ID, Tag: tag})
+		if err != nil {
+			return fmt.Errorf("couldn't untag '%s': %w", saved.Title, err)
+		}
+		fmt.Printf("Removed tag %q from '%s'\n", tag, saved.Title)
+	}
+
+	return nil
+}
+
+func handlerUnsave(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %v <post id|url>...", cmd.Name)
+	}
+
+	ctx := context.Background()
+	for _, ref := range cmd.Args {
+		saved, found, err := findSavedPost(ctx, s, user, ref)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("you haven't saved a post with URL or ID '%s'", ref)
+		}
+
+		if err := s.db.DeleteSavedPost(ctx, saved.ID); err != nil {
+			return fmt.Errorf("couldn't unsave '%s': %w", saved.Title, err)
+		}
+		fmt.Printf("Unsaved '%s'\n", saved.Title)
+	}
+	return nil
+}
+
+// savedRecord is a saved post as saved prints it with --output. Tags are
+// comma separated.
+type savedRecord struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	FeedName    string     `json:"feed_name"`
+	PublishedAt *time.Time `json:"published_at"`
+	SavedAt     time.Time  `json:"saved_at"`
+	Tags        string     `json:"tags"`
+	Note        string     `json:"note"`
+}
+
+func handlerSaved(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd)
+	tag := fs.String("tag", "", "only show saves with this tag")
+	feedName := fs.String("feed", "", "only show saves from the feed with this name")
+	text := fs.String("text", "", "only show saves whose title or note contains this text")
+	since := fs.String("since", "", "only show posts saved on or after this date (2006-01-02) or this long ago (30d, 2w, 12h)")
+	until := fs.String("until", "", "only show posts saved on or before this date (2006-01-02) or this long ago")
+	limit := fs.Int("limit", 20, "maximum number of saves to show")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 0 || *limit < 1 {
+		return fmt.Errorf("usage: %v [--tag <tag>] [--feed <name>] [--text <text>] [--since <date|age>] [--until <date|age>] [--limit <n>]", cmd.Name)
+	}
+
+	ctx := context.Background()
+	loc := userLocation(user)
+
+	params := database.GetSavedPostsParams{
+		UserID:   user.ID,
+		Tag:      sql.NullString{String: normalizeCategory(*tag), Valid: *tag != ""},
+		FeedName: sql.NullString{String: strings.TrimSpace(*feedName), Valid: *feedName != ""},
+		Text:     sql.NullString{String: *text, Valid: *text != ""},
+		Limit:    int32(*limit),
+	}
+	if *since != "" {
+		t, err := parseDateFlag(*since, loc, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseDateFlag(*until, loc, true)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+
+	saves, err := s.db.GetSavedPosts(ctx, params)
+	if err != nil {
+		return fmt.Errorf("error getting saved posts: %w", err)
+	}
+
+	if s.output != outputText {
+		records := make([]savedRecord, len(saves))
+		for i, saved := range saves {
+			records[i] = savedRecord{
+				ID:          saved.PostID,
+				Title:       saved.Title,
+				URL:         saved.Url,
+				FeedName:    saved.FeedName,
+				PublishedAt: timeField(saved.PublishedAt),
+				SavedAt:     saved.CreatedAt.UTC(),
+				Tags:        saved.Tags,
+				Note:        saved.Note,
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
+	if len(saves) == 0 {
+		fmt.Println("No saved posts found. Save one with: save <id>")
+		return nil
+	}
+
+	width := terminalWidth()
+
+	fmt.Printf("Saved posts, latest first (showing %d):\n\n", len(saves))
+	for i, saved := range saves {
+		fmt.Printf("=== %d ===\n", i+1)
+		fmt.Printf("ID: %s\n", shortID(saved.PostID))
+		fmt.Printf("Title: %s\n", saved.Title)
+		fmt.Printf("Feed: %s\n", saved.FeedName)
+
+		if saved.PublishedAt.Valid {
+			fmt.Printf("Published: %v\n", saved.PublishedAt.Time.In(loc).Format("January 2, 2006 15:04:05 MST"))
+		}
+		fmt.Printf("Saved: %v\n", saved.CreatedAt.In(loc).Format("January 2, 2006 15:04:05 MST"))
+
+		if saved.Tags != "" {
+			fmt.Printf("Tags: %s\n", saved.Tags)
+		}
+
+		fmt.Printf("URL: %s\n", saved.Url)
+
+		if saved.Note != "" {
+			fmt.Printf("Note: %s\n", saved.Note)
+		} else if saved.Description.Valid && saved.Description.String != "" {
+			printDescription(saved.Description.String, width, descriptionLimit)
+		}
+
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// findSavedPost looks up one of the user's saves by its post's URL, ID or the
+// start of the ID, reporting whether there was one
+func findSavedPost(ctx context.Context, s *state, user database.User, ref string) (database.SavedPost, bool, error) {
+	saved, err := s.db.GetSavedPostByURL(ctx, database.GetSavedPostByURLParams{UserID: user.ID, Url: ref})
+	if err == nil {
+		return saved, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return database.SavedPost{}, false, fmt.Errorf("couldn't look up saved post '%s': %w", ref, err)
+	}
+
+	lower, upper, ok := idPrefixRange(ref)
+	if !ok {
+		return database.SavedPost{}, false, nil
+	}
+
+	saves, err := s.db.GetSavedPostsByIDPrefix(ctx, database.GetSavedPostsByIDPrefixParams{
+		UserID: user.ID,
+		Lower:  lower,
+		Upper:  upper,
+	})
+	if err != nil {
+		return database.SavedPost{}, false, fmt.Errorf("couldn't look up saved post '%s': %w", ref, err)
+	}
+
+	switch len(saves) {
+	case 0:
+		return database.SavedPost{}, false, nil
+	case 1:
+		return saves[0], true, nil
+	default:
+		return database.SavedPost{}, false, fmt.Errorf("more than one saved post has an ID starting with '%s', give more of it", ref)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/htmltext"
+	"github.com/AlexTLDR/gator/internal/websearch"
+
+	"github.com/google/uuid"
+)
+
+// searchRecord is a post as search prints it with --output, best match
+// first
+type searchRecord struct {
+	ID                  uuid.UUID  `json:"id"`
+	Title               string     `json:"title"`
+	URL                 string     `json:"url"`
+	FeedName            string     `json:"feed_name"`
+	PublishedAt         *time.Time `json:"published_at"`
+	PublishedAtInferred bool       `json:"published_at_inferred"`
+	Snippet             string     `json:"snippet"`
+}
+
+func handlerSearch(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd)
+	feedRef := fs.String("feed", "", "only search posts of this feed (url or name)")
+	since := fs.String("since", "", "only posts published on or after this date (2006-01-02) or this long ago (30d, 2w, 12h)")
+	until := fs.String("until", "", "only posts published on or before this date (2006-01-02) or this long ago")
+	limit := fs.Int("limit", 10, "maximum number of results")
+
+	usage := fmt.Errorf(`usage: %v <query> [--feed <url|name>] [--since <date|age>] [--until <date|age>] [--limit <n>] (e.g. %v '"vector search" postgres -mysql')`, cmd.Name, cmd.Name)
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) == 0 || *limit < 1 {
+		return usage
+	}
+	query := strings.Join(args, " ")
+	if len(websearch.Parse(query)) == 0 {
+		return fmt.Errorf("nothing to search for in %q", query)
+	}
+
+	ctx := context.Background()
+	loc := userLocation(user)
+
+	params := database.SearchPostsParams{
+		Query:  query,
+		UserID: user.ID,
+		Limit:  int32(*limit),
+	}
+	if *feedRef != "" {
+		feed, err := findFeed(ctx, s, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if *since != "" {
+		t, err := parseDateFlag(*since, loc, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseDateFlag(*until, loc, true)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+
+	posts, err := s.db.SearchPosts(ctx, params)
+	if err != nil {
+		return fmt.Errorf("error searching posts: %w", err)
+	}
+
+	if s.output != outputText {
+		// Drop the highlight markers, which only make sense on a terminal
+		unmark := strings.NewReplacer(websearch.HighlightStart, "", websearch.HighlightStop, "")
+		records := make([]searchRecord, len(posts))
+		for i, post := range posts {
+			records[i] = searchRecord{
+				ID:                  post.ID,
+				Title:               post.Title,
+				URL:                 post.Url,
+				FeedName:            post.FeedName,
+				PublishedAt:         timeField(post.PublishedAt),
+				PublishedAtInferred: post.PublishedAtInferred,
+				Snippet:             unmark.Replace(htmltext.Render(post.Snippet).Text),
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
+	if len(posts) == 0 {
+		fmt.Printf("No posts from feeds you follow match %q.\n", query)
+		return nil
+	}
+
+	width := terminalWidth()
+	bold := isTerminal()
+
+	fmt.Printf("Posts matching %q, best first (showing %d):\n\n", query, len(posts))
+	for i, post := range posts {
+		fmt.Printf("=== %d ===\n", i+1)
+		fmt.Printf("Title: %s\n", post.Title)
+		fmt.Printf("Feed: %s\n", post.FeedName)
+
+		if post.PublishedAt.Valid {
+			published := post.PublishedAt.Time.In(loc).Format("January 2, 2006 15:04:05 MST")
+			if post.PublishedAtInferred {
+				published += " (estimated, the feed gave no usable date)"
+			}
+			fmt.Printf("Published: %v\n", published)
+		}
+
+		fmt.Printf("URL: %s\n", post.Url)
+
+		// Snippets are cut from the stored HTML, so render them like browse
+		// does before marking up the matches
+		if text := htmltext.Render(post.Snippet).Text; text != "" {
+			fmt.Printf("Match:\n%s\n", highlightMatches(htmltext.Wrap(text, width), bold))
+		}
+
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// highlightMatches replaces the markers around matched words with bold text
+// on a terminal, or with asterisks when output is piped
+func highlightMatches(text string, bold bool) string {
+	start, stop := "*", "*"
+	if bold {
+		start, stop = "\033[1m", "\033[0m"
+	}
+	return strings.NewReplacer(websearch.HighlightStart, start, websearch.HighlightStop, stop).Replace(text)
+}
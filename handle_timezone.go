@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	// Bundle the zone database so zone names work on machines without one
+	_ "time/tzdata"
+
+	"github.com/AlexTLDR/gator/internal/database"
+)
+
+func handlerTimezone(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %v [zone, e.g. Europe/Berlin, or 'local']", cmd.Name)
+	}
+
+	if len(cmd.Args) == 0 {
+		if user.Timezone == "" {
+			zone, _ := time.Now().Zone()
+			fmt.Printf("Times are shown in this machine's local zone (%s).\n", zone)
+		} else {
+			fmt.Printf("Times are shown in %s.\n", user.Timezone)
+		}
+		return nil
+	}
+
+	zone := cmd.Args[0]
+	if strings.EqualFold(zone, "local") {
+		zone = ""
+	} else if _, err := time.LoadLocation(zone); err != nil {
+		return fmt.Errorf("unknown time zone %q, use a name such as Europe/Berlin or America/New_York", zone)
+	}
+
+	err := s.db.SetUserTimezone(context.Background(), database.SetUserTimezoneParams{
+		Timezone:  zone,
+		UpdatedAt: time.Now().UTC(),
+		ID:        user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set time zone: %w", err)
+	}
+
+	if zone == "" {
+		fmt.Println("Times will be shown in this machine's local zone.")
+	} else {
+		fmt.Printf("Times will be shown in %s.\n", zone)
+	}
+	return nil
+}
+
+// userLocation returns the zone user wants times shown in, which is the
+// machine's local zone unless they picked one
+func userLocation(user database.User) *time.Location {
+	if user.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// currentUserLocation is userLocation for the logged in user, for commands
+// that don't require anyone to be logged in
+func currentUserLocation(ctx context.Context, s *state) *time.Location {
+	if s.cfg.CurrentUserName == "" {
+		return time.Local
+	}
+	user, err := s.db.GetUser(ctx, s.cfg.CurrentUserName)
+	if err != nil {
+		return time.Local
+	}
+	return userLocation(user)
+}
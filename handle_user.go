@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/AlexTLDR/gator/internal/database"
@@ -62,6 +63,14 @@ func printUser(user database.User) {
 	fmt.Printf(" * Name:    %v\n", user.Name)
 }
 
+// userRecord is a user as users prints it with --output
+type userRecord struct {
+	Name      string    `json:"name"`
+	Current   bool      `json:"current"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 func handlerUsers(s *state, cmd command) error {
 	// No arguments needed for this command
 	if len(cmd.Args) != 0 {
@@ -73,6 +82,20 @@ func handlerUsers(s *state, cmd command) error {
 		return fmt.Errorf("couldn't retrieve users: %w", err)
 	}
 
+	if s.output != outputText {
+		currentUser, _ := s.cfg.GetUser()
+		records := make([]userRecord, len(users))
+		for i, user := range users {
+			records[i] = userRecord{
+				Name:      user.Name,
+				Current:   user.Name == currentUser,
+				Timezone:  user.Timezone,
+				CreatedAt: user.CreatedAt.UTC(),
+			}
+		}
+		return printRecords(os.Stdout, s.output, records)
+	}
+
 	if len(users) == 0 {
 		fmt.Println("No users found in the database.")
 		return nil
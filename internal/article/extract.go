@@ -0,0 +1,251 @@
+// Package article pulls the main content out of a web page, in the spirit of
+// Readability: boilerplate such as navigation, sidebars and comments is
+// dropped and the densest block of paragraphs is kept.
+package article
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/AlexTLDR/gator/internal/htmltext"
+)
+
+// ErrNoContent is returned when a page has no block of text that looks like
+// an article
+var ErrNoContent = errors.New("no article content found")
+
+// minArticleLength is the number of characters of text a candidate needs
+// before it's accepted as the article
+const minArticleLength = 250
+
+var (
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text`)
+	negativeHint = regexp.MustCompile(`(?i)ad-|banner|combx|comment|community|contact|footer|footnote|menu|meta|nav|promo|related|share|sidebar|social|sponsor|subscribe|widget`)
+)
+
+// removedElements never contain article text
+var removedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+	atom.Form:     true,
+	atom.Iframe:   true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Svg:      true,
+}
+
+// Extract finds the main content of an HTML page and returns it as sanitized
+// HTML. Relative links and images are resolved against pageURL.
+func Extract(page []byte, pageURL string) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return "", err
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	clean(doc)
+
+	best := semanticCandidate(doc)
+	if best == nil {
+		best = scoredCandidate(doc)
+	}
+	if best == nil || len(textOf(best)) < minArticleLength {
+		return "", ErrNoContent
+	}
+
+	resolveURLs(best, base)
+
+	var buf bytes.Buffer
+	for c := best.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return "", err
+		}
+	}
+
+	content := htmltext.Sanitize(buf.String())
+	if content == "" {
+		return "", ErrNoContent
+	}
+	return content, nil
+}
+
+// clean removes elements that never hold article text, along with anything
+// whose class or id marks it as boilerplate
+func clean(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode ||
+			(c.Type == html.ElementNode && (removedElements[c.DataAtom] || isBoilerplate(c))) {
+			n.RemoveChild(c)
+		} else {
+			clean(c)
+		}
+		c = next
+	}
+}
+
+func isBoilerplate(n *html.Node) bool {
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Html || n.DataAtom == atom.Article {
+		return false
+	}
+	hints := attr(n, "class") + " " + attr(n, "id")
+	return negativeHint.MatchString(hints) && !positiveHint.MatchString(hints)
+}
+
+// semanticCandidate returns the page's single <article> or <main> element
+// when the markup is explicit about where the content is
+func semanticCandidate(doc *html.Node) *html.Node {
+	for _, a := range []atom.Atom{atom.Article, atom.Main} {
+		nodes := findAll(doc, a)
+		if len(nodes) == 1 && len(textOf(nodes[0])) >= minArticleLength {
+			return nodes[0]
+		}
+	}
+	return nil
+}
+
+// scoredCandidate scores the parents and grandparents of every paragraph by
+// how much prose they contain and returns the highest scoring one
+func scoredCandidate(doc *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node
+
+	add := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = classWeight(n)
+			order = append(order, n)
+		}
+		scores[n] += score
+	}
+
+	for _, p := range findAll(doc, atom.P, atom.Pre, atom.Td, atom.Blockquote) {
+		text := textOf(p)
+		if len(text) < 25 {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		add(p.Parent, score)
+		if p.Parent != nil {
+			add(p.Parent.Parent, score/2)
+		}
+	}
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, n := range order {
+		score := scores[n] * (1 - linkDensity(n))
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, hint := range []string{attr(n, "class"), attr(n, "id")} {
+		if hint == "" {
+			continue
+		}
+		if positiveHint.MatchString(hint) {
+			weight += 25
+		}
+		if negativeHint.MatchString(hint) {
+			weight -= 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of a node's text that sits inside links
+func linkDensity(n *html.Node) float64 {
+	total := len(textOf(n))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	for _, a := range findAll(n, atom.A) {
+		linked += len(textOf(a))
+	}
+	return float64(linked) / float64(total)
+}
+
+// resolveURLs makes every link and image URL under n absolute
+func resolveURLs(n *html.Node, base *url.URL) {
+	if n.Type == html.ElementNode {
+		for i, a := range n.Attr {
+			if a.Key != "href" && a.Key != "src" {
+				continue
+			}
+			if ref, err := url.Parse(strings.TrimSpace(a.Val)); err == nil {
+				n.Attr[i].Val = base.ResolveReference(ref).String()
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		resolveURLs(c, base)
+	}
+}
+
+func findAll(n *html.Node, atoms ...atom.Atom) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, a := range atoms {
+				if n.DataAtom == a {
+					found = append(found, n)
+					break
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// textOf returns the whitespace-collapsed text under n
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package article
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "the page's article element",
+			fixture: "semantic.html",
+			want: []string{
+				"happy to release Go 1.24",
+				"less time in the garbage collector",
+				`<a href="https://go.dev/doc/go1.24">release notes</a>`,
+				`src="https://go.dev/blog/images/gopher.png"`,
+			},
+			notWant: []string{"trackPageView", "font-family", "Home", "Share this", "Popular posts", "First!", "Copyright", "editor's note"},
+		},
+		{
+			name:    "the densest block of prose",
+			fixture: "scored.html",
+			want:    []string{"voted on Tuesday", "trees, shade and playgrounds", "within two years"},
+			notWant: []string{"Example News", "World news", "Another story"},
+		},
+		{
+			name:    "a page cut off part way",
+			fixture: "truncated.html",
+			want:    []string{"cut off by the download size limit", "close what was left open", "in the middle of a sent"},
+			notWant: []string{"Home"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(readFixture(t, tt.fixture), "https://go.dev/blog/go1.24")
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("content is missing %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("content has %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTruncatedIsWellFormed(t *testing.T) {
+	got, err := Extract(readFixture(t, "truncated.html"), "https://example.com/long-read")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if strings.Count(got, "<p>") != strings.Count(got, "</p>") {
+		t.Errorf("paragraphs left open:\n%s", got)
+	}
+}
+
+func TestExtractNoContent(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+	}{
+		{"fixture", string(readFixture(t, "no_content.html"))},
+		{"empty page", ""},
+		{"only boilerplate", "<nav>" + strings.Repeat("<a href='/x'>A link to somewhere else entirely</a> ", 20) + "</nav>"},
+		{"short article", "<article><p>Too short to be the article.</p></article>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract([]byte(tt.page), "https://example.com/")
+			if !errors.Is(err, ErrNoContent) {
+				t.Errorf("Extract = %q, %v; want ErrNoContent", got, err)
+			}
+		})
+	}
+}
+
+func TestExtractInvalidURL(t *testing.T) {
+	if _, err := Extract(readFixture(t, "semantic.html"), "http://[::1"); err == nil {
+		t.Error("Extract accepted an invalid page URL")
+	}
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	page, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
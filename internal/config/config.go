@@ -11,6 +11,16 @@ const configFileName = ".gatorconfig.json"
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	LogFormat       string `json:"log_format,omitempty"`
+	LogLevel        string `json:"log_level,omitempty"`
+	MetricsAddr     string `json:"metrics_addr,omitempty"`
+	HealthAddr      string `json:"health_addr,omitempty"`
+	AutoMigrate     bool   `json:"auto_migrate,omitempty"`
+	RetentionDays   int    `json:"retention_days,omitempty"`
+	RetentionKeep   int    `json:"retention_keep,omitempty"`
+
+	// Templates are named browse --format templates
+	Templates map[string]string `json:"templates,omitempty"`
 }
 
 func (cfg *Config) SetUser(userName string) error {
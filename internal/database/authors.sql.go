@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: authors.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addPostAuthor = `-- name: AddPostAuthor :exec
+INSERT INTO post_authors (post_id, author_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddPostAuthorParams struct {
+	PostID   uuid.UUID
+	AuthorID uuid.UUID
+}
+
+func (q *Queries) AddPostAuthor(ctx context.Context, arg AddPostAuthorParams) error {
+	_, err := q.db.ExecContext(ctx, addPostAuthor, arg.PostID, arg.AuthorID)
+	return err
+}
+
+const getAuthorsForPost = `-- name: GetAuthorsForPost :many
+SELECT a.id, a.created_at, a.name, a.email FROM authors a
+JOIN post_authors pa ON a.id = pa.author_id
+WHERE pa.post_id = $1
+ORDER BY a.name
+`
+
+func (q *Queries) GetAuthorsForPost(ctx context.Context, postID uuid.UUID) ([]Author, error) {
+	rows, err := q.db.QueryContext(ctx, getAuthorsForPost, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Author
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.Name,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertAuthor = `-- name: UpsertAuthor :one
+INSERT INTO authors (id, created_at, name, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (name, email) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, created_at, name, email
+`
+
+type UpsertAuthorParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Name      string
+	Email     string
+}
+
+func (q *Queries) UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (Author, error) {
+	row := q.db.QueryRowContext(ctx, upsertAuthor,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Name,
+		arg.Email,
+	)
+	var i Author
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Name,
+		&i.Email,
+	)
+	return i, err
+}
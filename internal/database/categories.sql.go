@@ -0,0 +1,79 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: categories.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addPostCategory = `-- name: AddPostCategory :exec
+INSERT INTO post_categories (post_id, category_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddPostCategoryParams struct {
+	PostID     uuid.UUID
+	CategoryID uuid.UUID
+}
+
+func (q *Queries) AddPostCategory(ctx context.Context, arg AddPostCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, addPostCategory, arg.PostID, arg.CategoryID)
+	return err
+}
+
+const getCategoriesForPost = `-- name: GetCategoriesForPost :many
+SELECT c.id, c.created_at, c.name FROM categories c
+JOIN post_categories pc ON c.id = pc.category_id
+WHERE pc.post_id = $1
+ORDER BY c.name
+`
+
+func (q *Queries) GetCategoriesForPost(ctx context.Context, postID uuid.UUID) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoriesForPost, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(&i.ID, &i.CreatedAt, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCategory = `-- name: UpsertCategory :one
+INSERT INTO categories (id, created_at, name)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, created_at, name
+`
+
+type UpsertCategoryParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Name      string
+}
+
+func (q *Queries) UpsertCategory(ctx context.Context, arg UpsertCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, upsertCategory, arg.ID, arg.CreatedAt, arg.Name)
+	var i Category
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.Name)
+	return i, err
+}
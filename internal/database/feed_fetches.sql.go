@@ -0,0 +1,142 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed_fetches.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFeedFetch = `-- name: CreateFeedFetch :one
+INSERT INTO feed_fetches (id, feed_id, started_at)
+VALUES ($1, $2, $3)
+RETURNING id, feed_id, started_at, finished_at, http_status, bytes, items_seen, items_inserted, error
+`
+
+type CreateFeedFetchParams struct {
+	ID        uuid.UUID
+	FeedID    uuid.UUID
+	StartedAt time.Time
+}
+
+func (q *Queries) CreateFeedFetch(ctx context.Context, arg CreateFeedFetchParams) (FeedFetch, error) {
+	row := q.db.QueryRowContext(ctx, createFeedFetch, arg.ID, arg.FeedID, arg.StartedAt)
+	var i FeedFetch
+	err := row.Scan(
+		&i.ID,
+		&i.FeedID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.HttpStatus,
+		&i.Bytes,
+		&i.ItemsSeen,
+		&i.ItemsInserted,
+		&i.Error,
+	)
+	return i, err
+}
+
+const finishFeedFetch = `-- name: FinishFeedFetch :exec
+UPDATE feed_fetches
+SET finished_at = $2,
+    http_status = $3,
+    bytes = $4,
+    items_seen = $5,
+    items_inserted = $6,
+    error = $7
+WHERE id = $1
+`
+
+type FinishFeedFetchParams struct {
+	ID            uuid.UUID
+	FinishedAt    sql.NullTime
+	HttpStatus    sql.NullInt32
+	Bytes         int64
+	ItemsSeen     int32
+	ItemsInserted int32
+	Error         sql.NullString
+}
+
+func (q *Queries) FinishFeedFetch(ctx context.Context, arg FinishFeedFetchParams) error {
+	_, err := q.db.ExecContext(ctx, finishFeedFetch,
+		arg.ID,
+		arg.FinishedAt,
+		arg.HttpStatus,
+		arg.Bytes,
+		arg.ItemsSeen,
+		arg.ItemsInserted,
+		arg.Error,
+	)
+	return err
+}
+
+const getFeedFetches = `-- name: GetFeedFetches :many
+SELECT ff.id, ff.feed_id, ff.started_at, ff.finished_at, ff.http_status, ff.bytes,
+       ff.items_seen, ff.items_inserted, ff.error,
+       f.name as feed_name, f.url as feed_url
+FROM feed_fetches ff
+JOIN feeds f ON ff.feed_id = f.id
+WHERE $1::uuid IS NULL OR ff.feed_id = $1
+ORDER BY ff.started_at DESC
+LIMIT $2
+`
+
+type GetFeedFetchesParams struct {
+	FeedID uuid.NullUUID
+	Limit  int32
+}
+
+type GetFeedFetchesRow struct {
+	ID            uuid.UUID
+	FeedID        uuid.UUID
+	StartedAt     time.Time
+	FinishedAt    sql.NullTime
+	HttpStatus    sql.NullInt32
+	Bytes         int64
+	ItemsSeen     int32
+	ItemsInserted int32
+	Error         sql.NullString
+	FeedName      string
+	FeedUrl       string
+}
+
+func (q *Queries) GetFeedFetches(ctx context.Context, arg GetFeedFetchesParams) ([]GetFeedFetchesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFetches, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFetchesRow
+	for rows.Next() {
+		var i GetFeedFetchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.HttpStatus,
+			&i.Bytes,
+			&i.ItemsSeen,
+			&i.ItemsInserted,
+			&i.Error,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -121,7 +121,15 @@ func (q *Queries) GetFeedFollowByUserAndFeed(ctx context.Context, arg GetFeedFol
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT ff.id, ff.created_at, ff.updated_at, ff.user_id, ff.feed_id, 
        u.name as user_name, 
-       f.name as feed_name
+       f.name as feed_name,
+       f.url as feed_url,
+       (
+           SELECT COUNT(*) FROM posts p
+           WHERE p.feed_id = ff.feed_id AND NOT EXISTS (
+               SELECT 1 FROM post_reads pr
+               WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+           )
+       ) as unread_count
 FROM feed_follows ff
 JOIN users u ON ff.user_id = u.id
 JOIN feeds f ON ff.feed_id = f.id
@@ -130,13 +138,15 @@ ORDER BY ff.created_at DESC
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	UserName  string
-	FeedName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	UserName    string
+	FeedName    string
+	FeedUrl     string
+	UnreadCount int64
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -156,6 +166,8 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.FeedID,
 			&i.UserName,
 			&i.FeedName,
+			&i.FeedUrl,
+			&i.UnreadCount,
 		); err != nil {
 			return nil, err
 		}
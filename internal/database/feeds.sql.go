@@ -13,6 +13,18 @@ import (
 	"github.com/google/uuid"
 )
 
+const countFeedsDueForFetch = `-- name: CountFeedsDueForFetch :one
+SELECT COUNT(*) FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at <= $1
+`
+
+func (q *Queries) CountFeedsDueForFetch(ctx context.Context, lastFetchedAt sql.NullTime) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedsDueForFetch, lastFetchedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -23,7 +35,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep
 `
 
 type CreateFeedParams struct {
@@ -53,6 +65,9 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullArticle,
+		&i.RetentionDays,
+		&i.RetentionKeep,
 	)
 	return i, err
 }
@@ -75,8 +90,47 @@ func (q *Queries) DeleteUserFeeds(ctx context.Context, userID uuid.UUID) error {
 	return err
 }
 
+const getAllFeeds = `-- name: GetAllFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds
+ORDER BY name, url
+`
+
+func (q *Queries) GetAllFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getAllFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.FetchFullArticle,
+			&i.RetentionDays,
+			&i.RetentionKeep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE id = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds WHERE id = $1
 `
 
 func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -90,12 +144,15 @@ func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullArticle,
+		&i.RetentionDays,
+		&i.RetentionKeep,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -109,25 +166,107 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullArticle,
+		&i.RetentionDays,
+		&i.RetentionKeep,
 	)
 	return i, err
 }
 
+const getFeedsByName = `-- name: GetFeedsByName :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds WHERE name = $1
+`
+
+func (q *Queries) GetFeedsByName(ctx context.Context, name string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.FetchFullArticle,
+			&i.RetentionDays,
+			&i.RetentionKeep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsDueForFetch = `-- name: GetFeedsDueForFetch :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at <= $1
+ORDER BY last_fetched_at ASC NULLS FIRST
+`
+
+func (q *Queries) GetFeedsDueForFetch(ctx context.Context, lastFetchedAt sql.NullTime) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsDueForFetch, lastFetchedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.FetchFullArticle,
+			&i.RetentionDays,
+			&i.RetentionKeep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedsWithUsers = `-- name: GetFeedsWithUsers :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, u.name as user_name
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, f.fetch_full_article, u.name as user_name
 FROM feeds f
 JOIN users u ON f.user_id = u.id
 ORDER BY f.created_at DESC
 `
 
 type GetFeedsWithUsersRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
-	UserName  string
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Name             string
+	Url              string
+	UserID           uuid.UUID
+	FetchFullArticle bool
+	UserName         string
 }
 
 func (q *Queries) GetFeedsWithUsers(ctx context.Context) ([]GetFeedsWithUsersRow, error) {
@@ -146,6 +285,7 @@ func (q *Queries) GetFeedsWithUsers(ctx context.Context) ([]GetFeedsWithUsersRow
 			&i.Name,
 			&i.Url,
 			&i.UserID,
+			&i.FetchFullArticle,
 			&i.UserName,
 		); err != nil {
 			return nil, err
@@ -162,7 +302,7 @@ func (q *Queries) GetFeedsWithUsers(ctx context.Context) ([]GetFeedsWithUsersRow
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT 1
 `
@@ -178,12 +318,15 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullArticle,
+		&i.RetentionDays,
+		&i.RetentionKeep,
 	)
 	return i, err
 }
 
 const getUserFeeds = `-- name: GetUserFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE user_id = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_article, retention_days, retention_keep FROM feeds WHERE user_id = $1
 `
 
 func (q *Queries) GetUserFeeds(ctx context.Context, userID uuid.UUID) ([]Feed, error) {
@@ -203,6 +346,9 @@ func (q *Queries) GetUserFeeds(ctx context.Context, userID uuid.UUID) ([]Feed, e
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.FetchFullArticle,
+			&i.RetentionDays,
+			&i.RetentionKeep,
 		); err != nil {
 			return nil, err
 		}
@@ -232,3 +378,43 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.ID)
 	return err
 }
+
+const setFeedFetchFullArticle = `-- name: SetFeedFetchFullArticle :exec
+UPDATE feeds
+SET fetch_full_article = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetFeedFetchFullArticleParams struct {
+	FetchFullArticle bool
+	UpdatedAt        time.Time
+	ID               uuid.UUID
+}
+
+func (q *Queries) SetFeedFetchFullArticle(ctx context.Context, arg SetFeedFetchFullArticleParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFetchFullArticle, arg.FetchFullArticle, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setFeedRetention = `-- name: SetFeedRetention :exec
+UPDATE feeds
+SET retention_days = $1, retention_keep = $2, updated_at = $3
+WHERE id = $4
+`
+
+type SetFeedRetentionParams struct {
+	RetentionDays sql.NullInt32
+	RetentionKeep sql.NullInt32
+	UpdatedAt     time.Time
+	ID            uuid.UUID
+}
+
+func (q *Queries) SetFeedRetention(ctx context.Context, arg SetFeedRetentionParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedRetention,
+		arg.RetentionDays,
+		arg.RetentionKeep,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
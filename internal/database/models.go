@@ -11,14 +11,42 @@ import (
 	"github.com/google/uuid"
 )
 
+type Author struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Name      string
+	Email     string
+}
+
+type Category struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Name      string
+}
+
 type Feed struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Name             string
+	Url              string
+	UserID           uuid.UUID
+	LastFetchedAt    sql.NullTime
+	FetchFullArticle bool
+	RetentionDays    sql.NullInt32
+	RetentionKeep    sql.NullInt32
+}
+
+type FeedFetch struct {
 	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	FeedID        uuid.UUID
+	StartedAt     time.Time
+	FinishedAt    sql.NullTime
+	HttpStatus    sql.NullInt32
+	Bytes         int64
+	ItemsSeen     int32
+	ItemsInserted int32
+	Error         sql.NullString
 }
 
 type FeedFollow struct {
@@ -30,14 +58,53 @@ type FeedFollow struct {
 }
 
 type Post struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	Content             sql.NullString
+	PublishedAtInferred bool
+	Search              interface{}
+}
+
+type PostAuthor struct {
+	PostID   uuid.UUID
+	AuthorID uuid.UUID
+}
+
+type PostCategory struct {
+	PostID     uuid.UUID
+	CategoryID uuid.UUID
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type SavedPost struct {
 	ID          uuid.UUID
+	UserID      uuid.UUID
+	PostID      uuid.UUID
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
-	Title       string
 	Url         string
-	Description sql.NullString
+	Title       string
+	FeedName    string
 	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	Description sql.NullString
+	Content     sql.NullString
+	Note        string
+}
+
+type SavedPostTag struct {
+	SavedPostID uuid.UUID
+	Tag         string
 }
 
 type User struct {
@@ -45,4 +112,5 @@ type User struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Name      string
+	Timezone  string
 }
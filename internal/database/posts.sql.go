@@ -13,24 +13,81 @@ import (
 	"github.com/google/uuid"
 )
 
+const countExpiredPosts = `-- name: CountExpiredPosts :one
+SELECT COUNT(*) FROM posts p
+WHERE p.feed_id = $1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = p.id)
+  AND (($2::timestamptz IS NOT NULL AND p.published_at < $2)
+    OR ($3::int IS NOT NULL AND p.id IN (
+        SELECT k.id FROM posts k
+        WHERE k.feed_id = $1
+        ORDER BY k.published_at DESC NULLS FIRST, k.id DESC
+        OFFSET $3
+    )))
+`
+
+type CountExpiredPostsParams struct {
+	FeedID uuid.UUID
+	Cutoff sql.NullTime
+	Keep   sql.NullInt32
+}
+
+func (q *Queries) CountExpiredPosts(ctx context.Context, arg CountExpiredPostsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countExpiredPosts, arg.FeedID, arg.Cutoff, arg.Keep)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countNewerPosts = `-- name: CountNewerPosts :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1 AND (published_at IS NULL OR published_at > $2)
+`
+
+type CountNewerPostsParams struct {
+	FeedID      uuid.UUID
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) CountNewerPosts(ctx context.Context, arg CountNewerPostsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countNewerPosts, arg.FeedID, arg.PublishedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, published_at_inferred, feed_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, published_at_inferred
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	PublishedAtInferred bool
+	FeedID              uuid.UUID
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
+type CreatePostRow struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	Content             sql.NullString
+	PublishedAtInferred bool
+}
+
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (CreatePostRow, error) {
 	row := q.db.QueryRowContext(ctx, createPost,
 		arg.ID,
 		arg.CreatedAt,
@@ -39,9 +96,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Url,
 		arg.Description,
 		arg.PublishedAt,
+		arg.PublishedAtInferred,
 		arg.FeedID,
 	)
-	var i Post
+	var i CreatePostRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -51,28 +109,72 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.PublishedAtInferred,
 	)
 	return i, err
 }
 
+const deleteExpiredPosts = `-- name: DeleteExpiredPosts :execrows
+DELETE FROM posts p
+WHERE p.feed_id = $1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = p.id)
+  AND (($2::timestamptz IS NOT NULL AND p.published_at < $2)
+    OR ($3::int IS NOT NULL AND p.id IN (
+        SELECT k.id FROM posts k
+        WHERE k.feed_id = $1
+        ORDER BY k.published_at DESC NULLS FIRST, k.id DESC
+        OFFSET $3
+    )))
+`
+
+type DeleteExpiredPostsParams struct {
+	FeedID uuid.UUID
+	Cutoff sql.NullTime
+	Keep   sql.NullInt32
+}
+
+func (q *Queries) DeleteExpiredPosts(ctx context.Context, arg DeleteExpiredPostsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredPosts, arg.FeedID, arg.Cutoff, arg.Keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deletePostsByFeedID = `-- name: DeletePostsByFeedID :exec
 DELETE FROM posts
 WHERE feed_id = $1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = posts.id)
 `
 
+// Saved posts are kept
 func (q *Queries) DeletePostsByFeedID(ctx context.Context, feedID uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, deletePostsByFeedID, feedID)
 	return err
 }
 
 const getPostByURL = `-- name: GetPostByURL :one
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, published_at_inferred FROM posts
 WHERE url = $1
 `
 
-func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
+type GetPostByURLRow struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	Content             sql.NullString
+	PublishedAtInferred bool
+}
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (GetPostByURLRow, error) {
 	row := q.db.QueryRowContext(ctx, getPostByURL, url)
-	var i Post
+	var i GetPostByURLRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -82,10 +184,77 @@ func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.PublishedAtInferred,
 	)
 	return i, err
 }
 
+const getPostsByIDPrefix = `-- name: GetPostsByIDPrefix :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.published_at_inferred
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1
+  AND p.id BETWEEN $2::uuid AND $3::uuid
+ORDER BY p.id
+LIMIT 2
+`
+
+type GetPostsByIDPrefixParams struct {
+	UserID uuid.UUID
+	Lower  uuid.UUID
+	Upper  uuid.UUID
+}
+
+type GetPostsByIDPrefixRow struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	Content             sql.NullString
+	PublishedAtInferred bool
+}
+
+// Posts of feeds the user follows whose ID starts with a prefix, given as the
+// smallest and largest IDs with it so that the primary key index is used
+func (q *Queries) GetPostsByIDPrefix(ctx context.Context, arg GetPostsByIDPrefixParams) ([]GetPostsByIDPrefixRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByIDPrefix, arg.UserID, arg.Lower, arg.Upper)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsByIDPrefixRow
+	for rows.Next() {
+		var i GetPostsByIDPrefixRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.PublishedAtInferred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsCount = `-- name: GetPostsCount :one
 SELECT COUNT(*) FROM posts
 `
@@ -98,35 +267,114 @@ func (q *Queries) GetPostsCount(ctx context.Context) (int64, error) {
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, 
-       p.feed_id, f.name as feed_name
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.content, p.published_at, 
+       p.published_at_inferred, p.feed_id, f.name as feed_name,
+       COALESCE((
+           SELECT string_agg(c.name, ', ' ORDER BY c.name)
+           FROM post_categories pc
+           JOIN categories c ON pc.category_id = c.id
+           WHERE pc.post_id = p.id
+       ), '')::text as categories,
+       COALESCE((
+           SELECT string_agg(a.name, ', ' ORDER BY a.name)
+           FROM post_authors pa
+           JOIN authors a ON pa.author_id = a.id
+           WHERE pa.post_id = p.id
+       ), '')::text as authors,
+       EXISTS (
+           SELECT 1 FROM post_reads pr
+           WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+       ) as read
 FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1
-ORDER BY p.published_at DESC
-LIMIT $2
+  AND (NOT $2::bool OR NOT EXISTS (
+      SELECT 1 FROM post_reads pr
+      WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+  ))
+  AND ($3::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_categories pc
+      JOIN categories c ON pc.category_id = c.id
+      WHERE pc.post_id = p.id AND c.name = lower($3)
+  ))
+  AND ($4::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_authors pa
+      JOIN authors a ON pa.author_id = a.id
+      WHERE pa.post_id = p.id
+        AND (lower(a.name) = lower($4) OR lower(a.email) = lower($4))
+  ))
+  AND ($5::uuid IS NULL OR p.feed_id = $5)
+  AND ($6::timestamptz IS NULL OR p.published_at >= $6)
+  AND ($7::timestamptz IS NULL OR p.published_at < $7)
+  AND (NOT $8::bool OR EXISTS (
+      SELECT 1 FROM saved_posts s
+      WHERE s.post_id = p.id AND s.user_id = ff.user_id
+  ))
+  -- Only posts after the cursor (before_published_at, before_id) in the
+  -- published order below, where a NULL date sorts first
+  AND ($9::uuid IS NULL
+       OR p.published_at < $10::timestamptz
+       OR (p.published_at = $10 AND p.id < $9)
+       OR ($10 IS NULL
+           AND (p.published_at IS NOT NULL OR p.id < $9)))
+ORDER BY CASE WHEN $11::text = 'feed' THEN f.name END ASC,
+         CASE WHEN $11 = 'fetched' THEN p.created_at END DESC,
+         p.published_at DESC NULLS FIRST, p.id DESC
+LIMIT $13 OFFSET $12
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID            uuid.UUID
+	UnreadOnly        bool
+	Tag               sql.NullString
+	Author            sql.NullString
+	FeedID            uuid.NullUUID
+	Since             sql.NullTime
+	Until             sql.NullTime
+	SavedOnly         bool
+	BeforeID          uuid.NullUUID
+	BeforePublishedAt sql.NullTime
+	Sort              string
+	Offset            int32
+	Limit             int32
 }
 
 type GetPostsForUserRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-	FeedName    string
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	Content             sql.NullString
+	PublishedAt         sql.NullTime
+	PublishedAtInferred bool
+	FeedID              uuid.UUID
+	FeedName            string
+	Categories          string
+	Authors             string
+	Read                bool
 }
 
+// sort is 'published', 'fetched' (newest stored first) or 'feed' (by feed
+// name, then published)
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Tag,
+		arg.Author,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.SavedOnly,
+		arg.BeforeID,
+		arg.BeforePublishedAt,
+		arg.Sort,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -141,9 +389,14 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Title,
 			&i.Url,
 			&i.Description,
+			&i.Content,
 			&i.PublishedAt,
+			&i.PublishedAtInferred,
 			&i.FeedID,
 			&i.FeedName,
+			&i.Categories,
+			&i.Authors,
+			&i.Read,
 		); err != nil {
 			return nil, err
 		}
@@ -157,3 +410,95 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT p.id, p.title, p.url, p.published_at, p.published_at_inferred, f.name AS feed_name,
+       ts_headline('english', coalesce(p.content, p.description, p.title),
+           websearch_to_tsquery('english', $1),
+           'StartSel=⟪, StopSel=⟫, MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=" … "'
+       )::text AS snippet
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = $2
+  AND p.search @@ websearch_to_tsquery('english', $1)
+  AND ($3::uuid IS NULL OR p.feed_id = $3)
+  AND ($4::timestamptz IS NULL OR p.published_at >= $4)
+  AND ($5::timestamptz IS NULL OR p.published_at < $5)
+ORDER BY ts_rank_cd(p.search, websearch_to_tsquery('english', $1)) DESC, p.published_at DESC
+LIMIT $6
+`
+
+type SearchPostsParams struct {
+	Query  string
+	UserID uuid.UUID
+	FeedID uuid.NullUUID
+	Since  sql.NullTime
+	Until  sql.NullTime
+	Limit  int32
+}
+
+type SearchPostsRow struct {
+	ID                  uuid.UUID
+	Title               string
+	Url                 string
+	PublishedAt         sql.NullTime
+	PublishedAtInferred bool
+	FeedName            string
+	Snippet             string
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.Query,
+		arg.UserID,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.PublishedAtInferred,
+			&i.FeedName,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePostContent = `-- name: UpdatePostContent :exec
+UPDATE posts
+SET content = $1, updated_at = $2
+WHERE id = $3
+`
+
+type UpdatePostContentParams struct {
+	Content   sql.NullString
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) UpdatePostContent(ctx context.Context, arg UpdatePostContentParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostContent, arg.Content, arg.UpdatedAt, arg.ID)
+	return err
+}
@@ -0,0 +1,82 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Querier interface {
+	AddPostAuthor(ctx context.Context, arg AddPostAuthorParams) error
+	AddPostCategory(ctx context.Context, arg AddPostCategoryParams) error
+	AddSavedPostTag(ctx context.Context, arg AddSavedPostTagParams) error
+	CountExpiredPosts(ctx context.Context, arg CountExpiredPostsParams) (int64, error)
+	CountFeedsDueForFetch(ctx context.Context, lastFetchedAt sql.NullTime) (int64, error)
+	CountNewerPosts(ctx context.Context, arg CountNewerPostsParams) (int64, error)
+	CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error)
+	CreateFeedFetch(ctx context.Context, arg CreateFeedFetchParams) (FeedFetch, error)
+	CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error)
+	CreatePost(ctx context.Context, arg CreatePostParams) (CreatePostRow, error)
+	CreateSavedPost(ctx context.Context, arg CreateSavedPostParams) (SavedPost, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteAllUsers(ctx context.Context) error
+	DeleteExpiredPosts(ctx context.Context, arg DeleteExpiredPostsParams) (int64, error)
+	DeleteFeed(ctx context.Context, id uuid.UUID) error
+	DeleteFeedFollow(ctx context.Context, id uuid.UUID) error
+	DeleteFeedFollowByUserAndFeedURL(ctx context.Context, arg DeleteFeedFollowByUserAndFeedURLParams) error
+	// Saved posts are kept
+	DeletePostsByFeedID(ctx context.Context, feedID uuid.UUID) error
+	DeleteSavedPost(ctx context.Context, id uuid.UUID) error
+	DeleteUserFeeds(ctx context.Context, userID uuid.UUID) error
+	FinishFeedFetch(ctx context.Context, arg FinishFeedFetchParams) error
+	GetAllFeeds(ctx context.Context) ([]Feed, error)
+	GetAuthorsForPost(ctx context.Context, postID uuid.UUID) ([]Author, error)
+	GetCategoriesForPost(ctx context.Context, postID uuid.UUID) ([]Category, error)
+	GetFeed(ctx context.Context, id uuid.UUID) (Feed, error)
+	GetFeedByURL(ctx context.Context, url string) (Feed, error)
+	GetFeedFetches(ctx context.Context, arg GetFeedFetchesParams) ([]GetFeedFetchesRow, error)
+	GetFeedFollowByUserAndFeed(ctx context.Context, arg GetFeedFollowByUserAndFeedParams) (FeedFollow, error)
+	GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error)
+	GetFeedsByName(ctx context.Context, name string) ([]Feed, error)
+	GetFeedsDueForFetch(ctx context.Context, lastFetchedAt sql.NullTime) ([]Feed, error)
+	GetFeedsWithUsers(ctx context.Context) ([]GetFeedsWithUsersRow, error)
+	GetNextFeedToFetch(ctx context.Context) (Feed, error)
+	GetPostByURL(ctx context.Context, url string) (GetPostByURLRow, error)
+	// Posts of feeds the user follows whose ID starts with a prefix, given as the
+	// smallest and largest IDs with it so that the primary key index is used
+	GetPostsByIDPrefix(ctx context.Context, arg GetPostsByIDPrefixParams) ([]GetPostsByIDPrefixRow, error)
+	GetPostsCount(ctx context.Context) (int64, error)
+	// sort is 'published', 'fetched' (newest stored first) or 'feed' (by feed
+	// name, then published)
+	GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error)
+	GetSavedPost(ctx context.Context, arg GetSavedPostParams) (SavedPost, error)
+	GetSavedPostByURL(ctx context.Context, arg GetSavedPostByURLParams) (SavedPost, error)
+	GetSavedPosts(ctx context.Context, arg GetSavedPostsParams) ([]GetSavedPostsRow, error)
+	// The user's saves of posts whose ID starts with a prefix, given as the
+	// smallest and largest IDs with it so that the (user_id, post_id) index is
+	// used
+	GetSavedPostsByIDPrefix(ctx context.Context, arg GetSavedPostsByIDPrefixParams) ([]SavedPost, error)
+	GetUser(ctx context.Context, name string) (User, error)
+	GetUserFeeds(ctx context.Context, userID uuid.UUID) ([]Feed, error)
+	GetUsers(ctx context.Context) ([]User, error)
+	MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error
+	MarkPostRead(ctx context.Context, arg MarkPostReadParams) error
+	MarkPostUnread(ctx context.Context, arg MarkPostUnreadParams) error
+	MarkPostsRead(ctx context.Context, arg MarkPostsReadParams) (int64, error)
+	RemoveSavedPostTag(ctx context.Context, arg RemoveSavedPostTagParams) error
+	SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error)
+	SetFeedFetchFullArticle(ctx context.Context, arg SetFeedFetchFullArticleParams) error
+	SetFeedRetention(ctx context.Context, arg SetFeedRetentionParams) error
+	SetSavedPostNote(ctx context.Context, arg SetSavedPostNoteParams) error
+	SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error
+	UpdatePostContent(ctx context.Context, arg UpdatePostContentParams) error
+	UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (Author, error)
+	UpsertCategory(ctx context.Context, arg UpsertCategoryParams) (Category, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,324 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: saved_posts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addSavedPostTag = `-- name: AddSavedPostTag :exec
+INSERT INTO saved_post_tags (saved_post_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddSavedPostTagParams struct {
+	SavedPostID uuid.UUID
+	Tag         string
+}
+
+func (q *Queries) AddSavedPostTag(ctx context.Context, arg AddSavedPostTagParams) error {
+	_, err := q.db.ExecContext(ctx, addSavedPostTag, arg.SavedPostID, arg.Tag)
+	return err
+}
+
+const createSavedPost = `-- name: CreateSavedPost :one
+INSERT INTO saved_posts (id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note)
+SELECT $1, $2, p.id, $3, $3,
+       p.url, p.title, f.name, p.published_at, p.description, p.content, $4
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+WHERE p.id = $5
+RETURNING id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note
+`
+
+type CreateSavedPostParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	Note      string
+	PostID    uuid.UUID
+}
+
+func (q *Queries) CreateSavedPost(ctx context.Context, arg CreateSavedPostParams) (SavedPost, error) {
+	row := q.db.QueryRowContext(ctx, createSavedPost,
+		arg.ID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.Note,
+		arg.PostID,
+	)
+	var i SavedPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Url,
+		&i.Title,
+		&i.FeedName,
+		&i.PublishedAt,
+		&i.Description,
+		&i.Content,
+		&i.Note,
+	)
+	return i, err
+}
+
+const deleteSavedPost = `-- name: DeleteSavedPost :exec
+DELETE FROM saved_posts
+WHERE id = $1
+`
+
+func (q *Queries) DeleteSavedPost(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteSavedPost, id)
+	return err
+}
+
+const getSavedPost = `-- name: GetSavedPost :one
+SELECT id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note FROM saved_posts
+WHERE user_id = $1 AND post_id = $2
+`
+
+type GetSavedPostParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) GetSavedPost(ctx context.Context, arg GetSavedPostParams) (SavedPost, error) {
+	row := q.db.QueryRowContext(ctx, getSavedPost, arg.UserID, arg.PostID)
+	var i SavedPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Url,
+		&i.Title,
+		&i.FeedName,
+		&i.PublishedAt,
+		&i.Description,
+		&i.Content,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getSavedPostByURL = `-- name: GetSavedPostByURL :one
+SELECT id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note FROM saved_posts
+WHERE user_id = $1 AND url = $2
+`
+
+type GetSavedPostByURLParams struct {
+	UserID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) GetSavedPostByURL(ctx context.Context, arg GetSavedPostByURLParams) (SavedPost, error) {
+	row := q.db.QueryRowContext(ctx, getSavedPostByURL, arg.UserID, arg.Url)
+	var i SavedPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Url,
+		&i.Title,
+		&i.FeedName,
+		&i.PublishedAt,
+		&i.Description,
+		&i.Content,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getSavedPosts = `-- name: GetSavedPosts :many
+SELECT s.id, s.user_id, s.post_id, s.created_at, s.updated_at, s.url, s.title, s.feed_name,
+       s.published_at, s.description, s.content, s.note,
+       COALESCE((
+           SELECT string_agg(t.tag, ', ' ORDER BY t.tag)
+           FROM saved_post_tags t
+           WHERE t.saved_post_id = s.id
+       ), '')::text as tags
+FROM saved_posts s
+WHERE s.user_id = $1
+  AND ($2::text IS NULL OR EXISTS (
+      SELECT 1 FROM saved_post_tags t
+      WHERE t.saved_post_id = s.id AND t.tag = $2
+  ))
+  AND ($3::text IS NULL OR lower(s.feed_name) = lower($3))
+  AND ($4::text IS NULL
+       OR s.title ILIKE '%' || $4 || '%'
+       OR s.note ILIKE '%' || $4 || '%')
+  AND ($5::timestamptz IS NULL OR s.created_at >= $5)
+  AND ($6::timestamptz IS NULL OR s.created_at < $6)
+ORDER BY s.created_at DESC
+LIMIT $7
+`
+
+type GetSavedPostsParams struct {
+	UserID   uuid.UUID
+	Tag      sql.NullString
+	FeedName sql.NullString
+	Text     sql.NullString
+	Since    sql.NullTime
+	Until    sql.NullTime
+	Limit    int32
+}
+
+type GetSavedPostsRow struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	PostID      uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Url         string
+	Title       string
+	FeedName    string
+	PublishedAt sql.NullTime
+	Description sql.NullString
+	Content     sql.NullString
+	Note        string
+	Tags        string
+}
+
+func (q *Queries) GetSavedPosts(ctx context.Context, arg GetSavedPostsParams) ([]GetSavedPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedPosts,
+		arg.UserID,
+		arg.Tag,
+		arg.FeedName,
+		arg.Text,
+		arg.Since,
+		arg.Until,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSavedPostsRow
+	for rows.Next() {
+		var i GetSavedPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Url,
+			&i.Title,
+			&i.FeedName,
+			&i.PublishedAt,
+			&i.Description,
+			&i.Content,
+			&i.Note,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSavedPostsByIDPrefix = `-- name: GetSavedPostsByIDPrefix :many
+SELECT id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note FROM saved_posts
+WHERE user_id = $1
+  AND post_id BETWEEN $2::uuid AND $3::uuid
+ORDER BY post_id
+LIMIT 2
+`
+
+type GetSavedPostsByIDPrefixParams struct {
+	UserID uuid.UUID
+	Lower  uuid.UUID
+	Upper  uuid.UUID
+}
+
+// The user's saves of posts whose ID starts with a prefix, given as the
+// smallest and largest IDs with it so that the (user_id, post_id) index is
+// used
+func (q *Queries) GetSavedPostsByIDPrefix(ctx context.Context, arg GetSavedPostsByIDPrefixParams) ([]SavedPost, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedPostsByIDPrefix, arg.UserID, arg.Lower, arg.Upper)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedPost
+	for rows.Next() {
+		var i SavedPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Url,
+			&i.Title,
+			&i.FeedName,
+			&i.PublishedAt,
+			&i.Description,
+			&i.Content,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeSavedPostTag = `-- name: RemoveSavedPostTag :exec
+DELETE FROM saved_post_tags
+WHERE saved_post_id = $1 AND tag = $2
+`
+
+type RemoveSavedPostTagParams struct {
+	SavedPostID uuid.UUID
+	Tag         string
+}
+
+func (q *Queries) RemoveSavedPostTag(ctx context.Context, arg RemoveSavedPostTagParams) error {
+	_, err := q.db.ExecContext(ctx, removeSavedPostTag, arg.SavedPostID, arg.Tag)
+	return err
+}
+
+const setSavedPostNote = `-- name: SetSavedPostNote :exec
+UPDATE saved_posts
+SET note = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetSavedPostNoteParams struct {
+	Note      string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetSavedPostNote(ctx context.Context, arg SetSavedPostNoteParams) error {
+	_, err := q.db.ExecContext(ctx, setSavedPostNote, arg.Note, arg.UpdatedAt, arg.ID)
+	return err
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// This file is written by hand; sqlc leaves it alone.
+
+// Store is a Querier that can also run several queries as one transaction.
+// Both SQL backends and the in-memory store implement it.
+type Store interface {
+	Querier
+	// InTx runs fn with a Querier whose queries all belong to one
+	// transaction, committed when fn returns nil and rolled back otherwise
+	InTx(ctx context.Context, fn func(Querier) error) error
+}
+
+// Tx is a transaction begun by a TxBeginner
+type Tx interface {
+	DBTX
+	Commit() error
+	Rollback() error
+}
+
+// TxBeginner is a DBTX that can begin a transaction whose queries it runs
+// the same way as its own
+type TxBeginner interface {
+	DBTX
+	BeginTx(ctx context.Context) (Tx, error)
+}
+
+// NewStore returns a Store running its queries on db
+func NewStore(db TxBeginner) Store {
+	return &sqlStore{Queries: New(db), db: db}
+}
+
+type sqlStore struct {
+	*Queries
+	db TxBeginner
+}
+
+func (s *sqlStore) InTx(ctx context.Context, fn func(Querier) error) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(New(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SQLDB returns db as a TxBeginner
+func SQLDB(db *sql.DB) TxBeginner {
+	return sqlDB{db}
+}
+
+type sqlDB struct {
+	*sql.DB
+}
+
+func (d sqlDB) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
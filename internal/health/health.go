@@ -0,0 +1,152 @@
+// Package health reports whether a long-running aggregator is alive and
+// keeping up, over HTTP and to systemd.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Pinger is satisfied by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Checker tracks aggregation cycles. The aggregator is ready while its last
+// successful cycle, or its start if none has succeeded yet, is no more than
+// maxMissed intervals ago, so one whose every fetch fails is reported as
+// well as one whose loop has stopped. When the last cycle ran and how it
+// failed are reported alongside, for information.
+type Checker struct {
+	db        Pinger
+	interval  time.Duration
+	maxMissed int
+
+	mu          sync.Mutex
+	started     time.Time
+	lastCycle   time.Time
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// NewChecker returns a Checker for an aggregator that runs a cycle every
+// interval
+func NewChecker(db Pinger, interval time.Duration, maxMissed int) *Checker {
+	return &Checker{
+		db:        db,
+		interval:  interval,
+		maxMissed: maxMissed,
+		started:   time.Now(),
+	}
+}
+
+// CycleCompleted records the end of an aggregation cycle, along with the
+// error it failed with, if any
+func (c *Checker) CycleCompleted(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastCycle = time.Now()
+	c.lastErr = err
+	if err == nil {
+		c.lastSuccess = c.lastCycle
+	}
+}
+
+// Ready reports whether a cycle has succeeded recently enough
+func (c *Checker) Ready() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	since := c.lastSuccess
+	if since.IsZero() {
+		since = c.started
+	}
+	return time.Since(since) <= time.Duration(c.maxMissed)*c.interval
+}
+
+// Alive reports whether the database answers a ping
+func (c *Checker) Alive(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return c.db.PingContext(ctx)
+}
+
+type status struct {
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	LastCycle   *time.Time `json:"last_cycle,omitempty"`
+	LastSuccess *time.Time `json:"last_successful_cycle,omitempty"`
+	LastError   string     `json:"last_cycle_error,omitempty"`
+}
+
+// HealthzHandler answers 200 while the process runs and the database
+// answers, and 503 otherwise
+func (c *Checker) HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := c.Alive(r.Context()); err != nil {
+			writeStatus(w, http.StatusServiceUnavailable, status{Status: "unhealthy", Error: "database: " + err.Error()})
+			return
+		}
+		writeStatus(w, http.StatusOK, status{Status: "ok"})
+	})
+}
+
+// ReadyzHandler answers 200 while aggregation cycles keep succeeding, and
+// 503 once too many intervals have passed without one. How the last cycle
+// went is reported either way.
+func (c *Checker) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ready := c.Ready()
+
+		c.mu.Lock()
+		st := status{Status: "ready"}
+		if !c.lastCycle.IsZero() {
+			last := c.lastCycle
+			st.LastCycle = &last
+		}
+		if !c.lastSuccess.IsZero() {
+			last := c.lastSuccess
+			st.LastSuccess = &last
+		}
+		if c.lastErr != nil {
+			st.LastError = c.lastErr.Error()
+		}
+		c.mu.Unlock()
+
+		if !ready {
+			st.Status = "not ready"
+			st.Error = "no aggregation cycle succeeded recently"
+			writeStatus(w, http.StatusServiceUnavailable, st)
+			return
+		}
+		writeStatus(w, http.StatusOK, st)
+	})
+}
+
+// Watchdog pings the systemd watchdog every period for as long as cycles
+// keep succeeding, so that systemd restarts the aggregator once it wedges
+// or every cycle fails
+func (c *Checker) Watchdog(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.Ready() {
+				Notify("WATCHDOG=1")
+			}
+		}
+	}
+}
+
+func writeStatus(w http.ResponseWriter, code int, st status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(st)
+}
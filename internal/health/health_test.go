@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pinger struct{ err error }
+
+func (p pinger) PingContext(context.Context) error { return p.err }
+
+func readyz(t *testing.T, c *Checker) (int, status) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.ReadyzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var st status
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatalf("couldn't decode /readyz response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, st
+}
+
+func TestReadyWhileCyclesSucceed(t *testing.T) {
+	c := NewChecker(pinger{}, time.Minute, 3)
+	if !c.Ready() {
+		t.Fatal("not ready right after starting")
+	}
+
+	c.CycleCompleted(nil)
+	code, st := readyz(t, c)
+	if code != http.StatusOK || st.Status != "ready" || st.LastSuccess == nil || st.LastError != "" {
+		t.Errorf("after a successful cycle /readyz = %d %+v, want 200 ready", code, st)
+	}
+
+	// A failed cycle is reported, but the recent success keeps it ready
+	c.CycleCompleted(errors.New("feed returned 503"))
+	code, st = readyz(t, c)
+	if code != http.StatusOK || st.LastCycle == nil || st.LastSuccess == nil || st.LastError != "feed returned 503" {
+		t.Errorf("after a failed cycle /readyz = %d %+v", code, st)
+	}
+}
+
+func TestNotReadyOnceCyclesStop(t *testing.T) {
+	c := NewChecker(pinger{}, time.Minute, 3)
+	c.started = time.Now().Add(-4 * time.Minute)
+	if c.Ready() {
+		t.Error("ready although no cycle succeeded in 3 intervals")
+	}
+
+	c.CycleCompleted(nil)
+	c.lastSuccess = time.Now().Add(-4 * time.Minute)
+	if code, _ := readyz(t, c); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz = %d once cycles stopped, want 503", code)
+	}
+
+	c.CycleCompleted(nil)
+	if !c.Ready() {
+		t.Error("not ready after a cycle succeeded again")
+	}
+}
+
+func TestNotReadyWhileCyclesFail(t *testing.T) {
+	c := NewChecker(pinger{}, time.Minute, 3)
+	c.started = time.Now().Add(-4 * time.Minute)
+
+	// Cycles keep completing, but none succeeds
+	c.CycleCompleted(errors.New("connection refused"))
+	code, st := readyz(t, c)
+	if code != http.StatusServiceUnavailable || st.Status != "not ready" {
+		t.Errorf("/readyz = %d %q while every cycle fails, want 503 not ready", code, st.Status)
+	}
+	if st.LastCycle == nil || st.LastSuccess != nil || st.LastError != "connection refused" {
+		t.Errorf("/readyz reported %+v", st)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		code int
+	}{
+		{nil, http.StatusOK},
+		{errors.New("connection refused"), http.StatusServiceUnavailable},
+	} {
+		c := NewChecker(pinger{tt.err}, time.Minute, 3)
+		rec := httptest.NewRecorder()
+		c.HealthzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if rec.Code != tt.code {
+			t.Errorf("/healthz with ping error %v = %d, want %d", tt.err, rec.Code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package htmltext
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Document is the plain-text rendering of an HTML fragment. Links are
+// referenced from Text as numbered footnotes, so Links[0] is "[1]".
+type Document struct {
+	Text  string
+	Links []string
+}
+
+// skippedElements have content that should never be shown to the reader
+var skippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Head:     true,
+	atom.Template: true,
+	atom.Object:   true,
+	atom.Svg:      true,
+}
+
+// rawTextElements run to their end tag even when written as self-closing,
+// as browsers read them
+var rawTextElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+}
+
+// blockElements start on a new paragraph
+var blockElements = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Div:        true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.Table:      true,
+	atom.Section:    true,
+	atom.Article:    true,
+	atom.Header:     true,
+	atom.Footer:     true,
+	atom.Figure:     true,
+	atom.Hr:         true,
+	atom.Dl:         true,
+}
+
+// lineElements start on a new line without a blank line before them
+var lineElements = map[atom.Atom]bool{
+	atom.Tr:         true,
+	atom.Dt:         true,
+	atom.Dd:         true,
+	atom.Figcaption: true,
+}
+
+type list struct {
+	ordered bool
+	count   int
+}
+
+type renderer struct {
+	b            strings.Builder
+	links        []string
+	lists        []list
+	anchors      []int
+	prefix       string
+	pendingBreak int
+	pendingSpace bool
+	skip         int
+	pre          int
+}
+
+// Render converts an HTML fragment into readable plain text. Paragraphs are
+// separated by blank lines, list items are bulleted or numbered, and links
+// become numbered footnote markers collected in Document.Links.
+func Render(s string) Document {
+	r := &renderer{}
+	z := html.NewTokenizer(strings.NewReader(s))
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				r.text(string(z.Raw()))
+			}
+			return Document{Text: strings.TrimSpace(r.b.String()), Links: r.links}
+		case html.TextToken:
+			if r.skip == 0 {
+				r.text(string(z.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if skippedElements[tok.DataAtom] {
+				if tt == html.StartTagToken || rawTextElements[tok.DataAtom] {
+					r.skip++
+				}
+				continue
+			}
+			if r.skip == 0 {
+				r.start(tok, tt == html.SelfClosingTagToken)
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if skippedElements[tok.DataAtom] {
+				if r.skip > 0 {
+					r.skip--
+				}
+				continue
+			}
+			if r.skip == 0 {
+				r.end(tok)
+			}
+		}
+	}
+}
+
+func (r *renderer) start(tok html.Token, selfClosing bool) {
+	switch {
+	case blockElements[tok.DataAtom]:
+		r.breakLine(2)
+	case lineElements[tok.DataAtom]:
+		r.breakLine(1)
+	}
+
+	switch tok.DataAtom {
+	case atom.Br:
+		r.pendingBreak++
+	case atom.Pre:
+		r.pre++
+	case atom.Ul, atom.Ol:
+		r.breakLine(1)
+		r.lists = append(r.lists, list{ordered: tok.DataAtom == atom.Ol})
+	case atom.Li:
+		r.breakLine(1)
+		depth := len(r.lists)
+		if depth == 0 {
+			r.prefix = "- "
+			return
+		}
+		current := &r.lists[depth-1]
+		current.count++
+		indent := strings.Repeat("  ", depth-1)
+		if current.ordered {
+			r.prefix = fmt.Sprintf("%s%d. ", indent, current.count)
+		} else {
+			r.prefix = indent + "- "
+		}
+	case atom.Td, atom.Th:
+		r.pendingSpace = true
+	case atom.A:
+		if selfClosing {
+			return
+		}
+		r.anchors = append(r.anchors, r.addLink(attr(tok, "href")))
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(tok, "alt")); alt != "" {
+			r.write("[image: " + alt + "]")
+		}
+	}
+}
+
+func (r *renderer) end(tok html.Token) {
+	switch tok.DataAtom {
+	case atom.Pre:
+		if r.pre > 0 {
+			r.pre--
+		}
+	case atom.Ul, atom.Ol:
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+		if len(r.lists) == 0 {
+			r.breakLine(2)
+		} else {
+			r.breakLine(1)
+		}
+		return
+	case atom.A:
+		if len(r.anchors) == 0 {
+			return
+		}
+		n := r.anchors[len(r.anchors)-1]
+		r.anchors = r.anchors[:len(r.anchors)-1]
+		if n > 0 {
+			r.pendingSpace = false
+			r.write(fmt.Sprintf("[%d]", n))
+		}
+		return
+	}
+
+	switch {
+	case blockElements[tok.DataAtom]:
+		r.breakLine(2)
+	case lineElements[tok.DataAtom]:
+		r.breakLine(1)
+	}
+}
+
+// addLink records a footnote link and returns its number, or 0 if the URL
+// isn't worth showing
+func (r *renderer) addLink(href string) int {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || !safeURL(href) {
+		return 0
+	}
+	for i, link := range r.links {
+		if link == href {
+			return i + 1
+		}
+	}
+	r.links = append(r.links, href)
+	return len(r.links)
+}
+
+func (r *renderer) text(s string) {
+	if r.pre > 0 {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if i > 0 {
+				r.pendingBreak++
+			}
+			if line != "" {
+				r.write(line)
+			}
+		}
+		return
+	}
+
+	if s == "" {
+		return
+	}
+	if isSpace(s[0]) {
+		r.pendingSpace = true
+	}
+	for _, word := range strings.Fields(s) {
+		r.write(word)
+		r.pendingSpace = true
+	}
+	if !isSpace(s[len(s)-1]) {
+		r.pendingSpace = false
+	}
+}
+
+// write appends content, first flushing any pending line breaks, list
+// prefix or separating space
+func (r *renderer) write(s string) {
+	started := r.b.Len() > 0
+	if started && r.pendingBreak > 0 {
+		r.b.WriteString(strings.Repeat("\n", r.pendingBreak))
+	} else if started && r.pendingSpace && r.prefix == "" {
+		r.b.WriteByte(' ')
+	}
+	r.pendingBreak = 0
+	r.pendingSpace = false
+
+	if r.prefix != "" {
+		r.b.WriteString(r.prefix)
+		r.prefix = ""
+	}
+	r.b.WriteString(s)
+}
+
+// breakLine asks for at least n newlines before the next content
+func (r *renderer) breakLine(n int) {
+	if r.pendingBreak < n {
+		r.pendingBreak = n
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package htmltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		text  string
+		links []string
+	}{
+		{
+			name: "plain text",
+			in:   "just   some\ntext",
+			text: "just some text",
+		},
+		{
+			name: "paragraphs are separated by blank lines",
+			in:   "<p>one</p><p>two</p>",
+			text: "one\n\ntwo",
+		},
+		{
+			name: "line breaks",
+			in:   "a<br>b<br/>c",
+			text: "a\nb\nc",
+		},
+		{
+			name: "entities are decoded",
+			in:   "<p>5 &lt; 6 &amp;&nbsp;more</p>",
+			text: "5 < 6 & more",
+		},
+		{
+			name: "self-closing script still hides up to its end tag",
+			in:   "<p>a</p><script/>alert(1)</script><p>b</p>",
+			text: "a\n\nb",
+		},
+		{
+			name: "script, style and iframe contents are hidden",
+			in:   "<p>a</p><script>alert(1)</script><style>p{}</style><iframe>frame</iframe><p>b</p>",
+			text: "a\n\nb",
+		},
+		{
+			name: "unordered list",
+			in:   "<ul><li>one</li><li>two</li></ul>",
+			text: "- one\n- two",
+		},
+		{
+			name: "ordered list",
+			in:   "<ol><li>one<li>two</ol><p>after</p>",
+			text: "1. one\n2. two\n\nafter",
+		},
+		{
+			name: "nested lists are indented",
+			in:   "<ul><li>a<ul><li>b</li></ul></li><li>c</li></ul>",
+			text: "- a\n  - b\n- c",
+		},
+		{
+			name:  "links become numbered footnotes",
+			in:    `<p>see <a href="https://a.example/">this</a> and <a href="https://b.example/">that</a></p>`,
+			text:  "see this[1] and that[2]",
+			links: []string{"https://a.example/", "https://b.example/"},
+		},
+		{
+			name:  "repeated links share a number",
+			in:    `<a href="https://a.example/">x</a> <a href="https://a.example/">y</a>`,
+			text:  "x[1] y[1]",
+			links: []string{"https://a.example/"},
+		},
+		{
+			name: "javascript and fragment links get no footnote",
+			in:   `<a href="javascript:alert(1)">x</a> <a href="#top">y</a>`,
+			text: "x y",
+		},
+		{
+			name: "images show their alt text",
+			in:   `<p>a <img src="x.png" alt="a cat"> b</p>`,
+			text: "a [image: a cat] b",
+		},
+		{
+			name: "preformatted text keeps its lines",
+			in:   "<pre>line one\n  line two</pre>",
+			text: "line one\n  line two",
+		},
+		{
+			name: "unclosed tags",
+			in:   "<p>one <b>two <ul><li>three",
+			text: "one two\n- three",
+		},
+		{
+			name: "unterminated tag at the end is dropped",
+			in:   "text <a href",
+			text: "text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Render(tt.in)
+			if doc.Text != tt.text {
+				t.Errorf("Render(%q).Text\n got %q\nwant %q", tt.in, doc.Text, tt.text)
+			}
+			if !reflect.DeepEqual(doc.Links, tt.links) {
+				t.Errorf("Render(%q).Links = %q, want %q", tt.in, doc.Links, tt.links)
+			}
+		})
+	}
+}
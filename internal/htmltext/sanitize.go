@@ -0,0 +1,181 @@
+package htmltext
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements maps every element that survives sanitization to the
+// attributes it may keep
+var allowedElements = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Abbr:       {"title"},
+	atom.B:          nil,
+	atom.Blockquote: {"cite"},
+	atom.Br:         nil,
+	atom.Code:       nil,
+	atom.Dd:         nil,
+	atom.Del:        nil,
+	atom.Dl:         nil,
+	atom.Dt:         nil,
+	atom.Em:         nil,
+	atom.Figcaption: nil,
+	atom.Figure:     nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Hr:         nil,
+	atom.I:          nil,
+	atom.Img:        {"src", "alt", "title", "width", "height"},
+	atom.Ins:        nil,
+	atom.Li:         nil,
+	atom.Ol:         {"start"},
+	atom.P:          nil,
+	atom.Pre:        nil,
+	atom.Q:          {"cite"},
+	atom.S:          nil,
+	atom.Small:      nil,
+	atom.Strong:     nil,
+	atom.Sub:        nil,
+	atom.Sup:        nil,
+	atom.Table:      nil,
+	atom.Tbody:      nil,
+	atom.Td:         {"colspan", "rowspan"},
+	atom.Tfoot:      nil,
+	atom.Th:         {"colspan", "rowspan"},
+	atom.Thead:      nil,
+	atom.Tr:         nil,
+	atom.U:          nil,
+	atom.Ul:         nil,
+}
+
+// urlAttributes hold URLs and must use a safe scheme
+var urlAttributes = map[string]bool{
+	"href": true,
+	"src":  true,
+	"cite": true,
+}
+
+var voidElements = map[atom.Atom]bool{
+	atom.Br:  true,
+	atom.Hr:  true,
+	atom.Img: true,
+}
+
+// Sanitize strips an HTML fragment down to an allowlist of formatting
+// elements and attributes. Scripts, styles and other active content are
+// removed along with their contents, disallowed elements are unwrapped so
+// their text is kept, and the result is always well formed.
+func Sanitize(s string) string {
+	var b strings.Builder
+	var open []atom.Atom
+	skip := 0
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF && skip == 0 {
+				b.WriteString(html.EscapeString(string(z.Raw())))
+			}
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i].String() + ">")
+			}
+			return strings.TrimSpace(b.String())
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if skippedElements[tok.DataAtom] {
+				if tt == html.StartTagToken || rawTextElements[tok.DataAtom] {
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedElements[tok.DataAtom]
+			if skip > 0 || !ok {
+				continue
+			}
+			if tok.DataAtom == atom.Img && !safeURL(attr(tok, "src")) {
+				continue
+			}
+			b.WriteString(startTag(tok, attrs))
+			if !voidElements[tok.DataAtom] && tt == html.StartTagToken {
+				open = append(open, tok.DataAtom)
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if skippedElements[tok.DataAtom] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// Close everything up to the matching element, ignoring
+			// end tags that were never opened
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.DataAtom {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j].String() + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+}
+
+func startTag(tok html.Token, allowed []string) string {
+	var b strings.Builder
+	b.WriteString("<" + tok.DataAtom.String())
+	for _, a := range tok.Attr {
+		if a.Namespace != "" || !contains(allowed, a.Key) {
+			continue
+		}
+		if urlAttributes[a.Key] && !safeURL(a.Val) {
+			continue
+		}
+		b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// safeURL reports whether a URL is relative or uses a scheme that can't run
+// code when followed
+func safeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package htmltext
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "allowed formatting is kept",
+			in:   `<p>Hello <strong>world</strong> and <em>you</em></p>`,
+			want: `<p>Hello <strong>world</strong> and <em>you</em></p>`,
+		},
+		{
+			name: "script is removed with its contents",
+			in:   `<p>before</p><script>alert("x")</script><p>after</p>`,
+			want: `<p>before</p><p>after</p>`,
+		},
+		{
+			name: "style is removed with its contents",
+			in:   `<style>p { color: red }</style><p>text</p>`,
+			want: `<p>text</p>`,
+		},
+		{
+			name: "iframe is removed with its contents",
+			in:   `<p>a</p><iframe src="https://evil.example/"><p>fallback</p></iframe><p>b</p>`,
+			want: `<p>a</p><p>b</p>`,
+		},
+		{
+			name: "self-closing script still runs to its end tag",
+			in:   `<script src="https://evil.example/x.js"/>alert(1)</script><p>kept</p>`,
+			want: `<p>kept</p>`,
+		},
+		{
+			name: "nested skipped elements",
+			in:   `<noscript><style>x</style>hidden</noscript>shown`,
+			want: `shown`,
+		},
+		{
+			name: "disallowed elements are unwrapped",
+			in:   `<div><span class="x">text</span></div>`,
+			want: `text`,
+		},
+		{
+			name: "event handlers and styles are dropped",
+			in:   `<p onclick="steal()" style="color:red">x</p>`,
+			want: `<p>x</p>`,
+		},
+		{
+			name: "javascript href is dropped",
+			in:   `<a href="javascript:alert(1)">link</a>`,
+			want: `<a>link</a>`,
+		},
+		{
+			name: "javascript href with mixed case and spaces is dropped",
+			in:   `<a href="  JaVaScRiPt:alert(1)">link</a>`,
+			want: `<a>link</a>`,
+		},
+		{
+			name: "entity-encoded javascript href is dropped",
+			in:   `<a href="&#106;avascript:alert(1)">link</a>`,
+			want: `<a>link</a>`,
+		},
+		{
+			name: "javascript href with an embedded tab is dropped",
+			in:   "<a href=\"java\tscript:alert(1)\">link</a>",
+			want: `<a>link</a>`,
+		},
+		{
+			name: "data href is dropped",
+			in:   `<a href="data:text/html,<script>x</script>">link</a>`,
+			want: `<a>link</a>`,
+		},
+		{
+			name: "safe links are kept",
+			in:   `<a href="https://example.com/?a=1&amp;b=2" title="t" target="_blank">x</a> <a href="/rel">y</a> <a href="mailto:a@example.com">z</a>`,
+			want: `<a href="https://example.com/?a=1&amp;b=2" title="t">x</a> <a href="/rel">y</a> <a href="mailto:a@example.com">z</a>`,
+		},
+		{
+			name: "image with javascript src is removed",
+			in:   `<p><img src="javascript:alert(1)" alt="x">text</p>`,
+			want: `<p>text</p>`,
+		},
+		{
+			name: "image with safe src is kept",
+			in:   `<img src="https://example.com/a.png" alt="a" onerror="x()">`,
+			want: `<img src="https://example.com/a.png" alt="a">`,
+		},
+		{
+			name: "unclosed tags are closed",
+			in:   `<p>one <b>two <i>three`,
+			want: `<p>one <b>two <i>three</i></b></p>`,
+		},
+		{
+			name: "mismatched end tag closes what it skips over",
+			in:   `<p><b>bold <i>both</b> after</i></p>`,
+			want: `<p><b>bold <i>both</i></b> after</p>`,
+		},
+		{
+			name: "end tags that were never opened are dropped",
+			in:   `text</b></p>`,
+			want: `text`,
+		},
+		{
+			name: "void elements aren't left open",
+			in:   `a<br>b<hr>c`,
+			want: `a<br>b<hr>c`,
+		},
+		{
+			name: "text is escaped",
+			in:   `5 &lt; 6 &amp; "x"`,
+			want: `5 &lt; 6 &amp; &#34;x&#34;`,
+		},
+		{
+			name: "unterminated tag at the end is dropped",
+			in:   `<p>text</p><a href="javascript:x`,
+			want: `<p>text</p>`,
+		},
+		{
+			name: "unterminated script hides the rest",
+			in:   `<p>a</p><script>alert(1)`,
+			want: `<p>a</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.in); got != tt.want {
+				t.Errorf("Sanitize(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeIsIdempotent(t *testing.T) {
+	inputs := []string{
+		`<p>one <b>two <i>three`,
+		`<a href="https://example.com/">x</a><script>y</script>`,
+		`<ul><li>a<li>b</ul>`,
+	}
+	for _, in := range inputs {
+		once := Sanitize(in)
+		if twice := Sanitize(once); twice != once {
+			t.Errorf("Sanitize isn't idempotent for %q:\n once %q\ntwice %q", in, once, twice)
+		}
+	}
+}
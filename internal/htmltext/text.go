@@ -0,0 +1,103 @@
+package htmltext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Truncate shortens s to at most max runes, cutting at a word boundary when
+// one is close and appending "..." when anything was removed
+func Truncate(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	runes := []rune(s)
+	cut := max - 3
+	if cut < 1 {
+		return string(runes[:max])
+	}
+
+	// Prefer breaking on whitespace if it doesn't lose too much text
+	for i := cut; i > cut*3/4; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "..."
+}
+
+// Wrap breaks every line of s so that none is wider than width runes, keeping
+// existing line breaks and the leading indentation of each line. Words longer
+// than width are left on a line of their own.
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+func wrapLine(line string, width int) []string {
+	if utf8.RuneCountInString(line) <= width {
+		return []string{line}
+	}
+
+	body := strings.TrimLeftFunc(line, unicode.IsSpace)
+	indent := line[:len(line)-len(body)]
+
+	// Continuation lines of list items line up with the item text
+	hanging := indent
+	if marker, _, ok := strings.Cut(body, " "); ok && isListMarker(marker) {
+		hanging += strings.Repeat(" ", utf8.RuneCountInString(marker)+1)
+	}
+
+	var lines []string
+	current := indent
+	currentLen := utf8.RuneCountInString(indent)
+	empty := true
+	for _, word := range strings.Fields(body) {
+		wordLen := utf8.RuneCountInString(word)
+		if !empty && currentLen+1+wordLen > width {
+			lines = append(lines, current)
+			current = hanging
+			currentLen = utf8.RuneCountInString(hanging)
+			empty = true
+		}
+		if !empty {
+			current += " "
+			currentLen++
+		}
+		current += word
+		currentLen += wordLen
+		empty = false
+	}
+	return append(lines, current)
+}
+
+func isListMarker(s string) bool {
+	if s == "-" {
+		return true
+	}
+	digits := strings.TrimSuffix(s, ".")
+	if digits == s || digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
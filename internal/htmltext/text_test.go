@@ -0,0 +1,132 @@
+package htmltext
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		max  int
+		want string
+	}{
+		{name: "short text is unchanged", in: "hello", max: 10, want: "hello"},
+		{name: "exact length is unchanged", in: "hello", max: 5, want: "hello"},
+		{name: "zero max", in: "hello", max: 0, want: ""},
+		{name: "max too small for an ellipsis", in: "hello", max: 3, want: "hel"},
+		{name: "cuts at a word boundary", in: "the quick brown fox jumps", max: 18, want: "the quick brown..."},
+		{name: "cuts mid-word when no space is close", in: "abcdefghijklmnopqrstuvwxyz", max: 10, want: "abcdefg..."},
+		{name: "multi-byte runes are kept whole", in: "héllo wörld ünïcödé", max: 14, want: "héllo wörld..."},
+		{name: "counts runes rather than bytes", in: "日本語のテキスト", max: 8, want: "日本語のテキスト"},
+		{name: "cuts CJK text by runes", in: "日本語のテキストです", max: 8, want: "日本語のテ..."},
+		{name: "emoji aren't split", in: "🙂🙂🙂🙂🙂🙂", max: 5, want: "🙂🙂..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.in, tt.max)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Truncate(%q, %d) = %q, which isn't valid UTF-8", tt.in, tt.max, got)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.max && tt.max > 0 {
+				t.Errorf("Truncate(%q, %d) is %d runes long", tt.in, tt.max, n)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{
+			name:  "short lines are unchanged",
+			in:    "one two\nthree",
+			width: 20,
+			want:  "one two\nthree",
+		},
+		{
+			name:  "zero width is unchanged",
+			in:    "one two three",
+			width: 0,
+			want:  "one two three",
+		},
+		{
+			name:  "breaks between words",
+			in:    "the quick brown fox jumps over the lazy dog",
+			width: 15,
+			want:  "the quick brown\nfox jumps over\nthe lazy dog",
+		},
+		{
+			name:  "keeps blank lines between paragraphs",
+			in:    "one two three\n\nfour five six",
+			width: 9,
+			want:  "one two\nthree\n\nfour five\nsix",
+		},
+		{
+			name:  "long words get a line of their own",
+			in:    "a supercalifragilistic word",
+			width: 10,
+			want:  "a\nsupercalifragilistic\nword",
+		},
+		{
+			name:  "keeps leading indentation",
+			in:    "    indented text that wraps",
+			width: 16,
+			want:  "    indented\n    text that\n    wraps",
+		},
+		{
+			name:  "bullet items hang under their text",
+			in:    "- first item that is long\n- second",
+			width: 14,
+			want:  "- first item\n  that is long\n- second",
+		},
+		{
+			name:  "numbered items hang under their text",
+			in:    "10. an ordered item that wraps",
+			width: 16,
+			want:  "10. an ordered\n    item that\n    wraps",
+		},
+		{
+			name:  "nested list items keep their indentation",
+			in:    "  - nested item that wraps",
+			width: 14,
+			want:  "  - nested\n    item that\n    wraps",
+		},
+		{
+			name:  "a word ending in a dot isn't a list marker",
+			in:    "end. of the sentence here",
+			width: 12,
+			want:  "end. of the\nsentence\nhere",
+		},
+		{
+			name:  "width counts runes",
+			in:    "ünïcödé ünïcödé ünïcödé",
+			width: 15,
+			want:  "ünïcödé ünïcödé\nünïcödé",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.in, tt.width)
+			if got != tt.want {
+				t.Errorf("Wrap(%q, %d)\n got %q\nwant %q", tt.in, tt.width, got, tt.want)
+			}
+			for _, line := range strings.Split(got, "\n") {
+				if n := utf8.RuneCountInString(line); tt.width > 0 && n > tt.width && len(strings.Fields(line)) > 1 {
+					t.Errorf("Wrap(%q, %d) has a %d-rune line %q", tt.in, tt.width, n, line)
+				}
+			}
+		})
+	}
+}
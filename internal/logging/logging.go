@@ -0,0 +1,69 @@
+// Package logging builds the slog loggers used by the aggregator.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Output formats
+const (
+	// FormatPretty is the friendly, emoji-prefixed format meant for terminals
+	FormatPretty = "pretty"
+	// FormatText is slog's key=value format
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line for log pipelines
+	FormatJSON = "json"
+	// FormatQuiet only reports errors, in the pretty format
+	FormatQuiet = "quiet"
+)
+
+// iconKey is the attribute carrying the emoji shown by the pretty format.
+// Other formats drop it.
+const iconKey = "icon"
+
+// Icon returns an attribute that sets the emoji the pretty format prefixes
+// a message with
+func Icon(icon string) slog.Attr {
+	return slog.String(iconKey, icon)
+}
+
+// New returns a logger writing to w in the given format. An empty format
+// means FormatPretty.
+func New(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	dropIcon := func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == iconKey {
+			return slog.Attr{}
+		}
+		return a
+	}
+
+	switch strings.ToLower(format) {
+	case "", FormatPretty:
+		return slog.New(NewPrettyHandler(w, level)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: dropIcon})), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: dropIcon})), nil
+	case FormatQuiet:
+		return slog.New(NewPrettyHandler(w, slog.LevelError)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (use pretty, text, json or quiet)", format)
+	}
+}
+
+// ParseLevel parses a level name such as "debug" or "warn". An empty name
+// means info.
+func ParseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+	}
+	return level, nil
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PrettyHandler renders records the way gator has always talked to a
+// terminal: an emoji, the message, and the values of the record's own
+// attributes. Attributes added with Logger.With, such as the feed being
+// fetched, are context for machine formats and aren't repeated on every line.
+type PrettyHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+}
+
+// NewPrettyHandler returns a PrettyHandler writing records at or above
+// level to w
+func NewPrettyHandler(w io.Writer, level slog.Leveler) *PrettyHandler {
+	return &PrettyHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *PrettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
+	icon := defaultIcon(r.Level)
+	var values []string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == iconKey {
+			icon = a.Value.String()
+			return true
+		}
+		if v := formatValue(a.Value); v != "" {
+			values = append(values, v)
+		}
+		return true
+	})
+
+	var b strings.Builder
+	if icon != "" {
+		b.WriteString(icon + " ")
+	}
+	b.WriteString(r.Message)
+	if len(values) > 0 {
+		b.WriteString(": " + strings.Join(values, ", "))
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *PrettyHandler) WithAttrs(_ []slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *PrettyHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+func defaultIcon(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "❌"
+	case level >= slog.LevelWarn:
+		return "⚠️"
+	case level < slog.LevelInfo:
+		return "🔍"
+	default:
+		return ""
+	}
+}
+
+func formatValue(v slog.Value) string {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339)
+	case slog.KindDuration:
+		return v.Duration().Round(time.Millisecond).String()
+	case slog.KindGroup:
+		var parts []string
+		for _, a := range v.Group() {
+			if s := formatValue(a.Value); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,1560 @@
+// Package memstore keeps gator's data in memory. It implements
+// database.Store with the same semantics as the SQL queries, including
+// unique and foreign key constraints and cascading deletes, so that code
+// written against the interface can run without a database server.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/htmltext"
+	"github.com/AlexTLDR/gator/internal/websearch"
+
+	"github.com/google/uuid"
+)
+
+// Store is an in-memory database.Store. The zero value is not usable; call
+// New.
+type Store struct {
+	mu sync.Mutex
+	// txMu lets one transaction run at a time
+	txMu sync.Mutex
+
+	tables
+}
+
+// tables holds the rows of every table
+type tables struct {
+	users          []database.User
+	feeds          []database.Feed
+	follows        []database.FeedFollow
+	posts          []database.Post
+	categories     []database.Category
+	postCategories []database.PostCategory
+	authors        []database.Author
+	postAuthors    []database.PostAuthor
+	fetches        []database.FeedFetch
+	reads          []database.PostRead
+	saved          []database.SavedPost
+	savedTags      []database.SavedPostTag
+}
+
+var _ database.Store = (*Store)(nil)
+
+// New returns an empty Store
+func New() *Store {
+	return &Store{}
+}
+
+// InTx runs fn against s, putting every table back as it was if fn returns
+// an error. Transactions are atomic but not isolated: queries made outside
+// one see its writes as they happen, and rolling it back also undoes any
+// writes they made meanwhile.
+func (s *Store) InTx(_ context.Context, fn func(database.Querier) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	saved := s.tables.clone()
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		s.tables = saved
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// clone returns a copy of t sharing none of its slices
+func (t *tables) clone() tables {
+	return tables{
+		users:          slices.Clone(t.users),
+		feeds:          slices.Clone(t.feeds),
+		follows:        slices.Clone(t.follows),
+		posts:          slices.Clone(t.posts),
+		categories:     slices.Clone(t.categories),
+		postCategories: slices.Clone(t.postCategories),
+		authors:        slices.Clone(t.authors),
+		postAuthors:    slices.Clone(t.postAuthors),
+		fetches:        slices.Clone(t.fetches),
+		reads:          slices.Clone(t.reads),
+		saved:          slices.Clone(t.saved),
+		savedTags:      slices.Clone(t.savedTags),
+	}
+}
+
+// Errors are worded like Postgres's, so callers that inspect the message,
+// such as the duplicate post check, behave the same with either store
+func errUnique(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", constraint)
+}
+
+func errForeignKey(constraint string) error {
+	return fmt.Errorf("insert or update violates foreign key constraint %q", constraint)
+}
+
+// Users
+
+func (s *Store) CreateUser(_ context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.ID == arg.ID {
+			return database.User{}, errUnique("users_pkey")
+		}
+		if u.Name == arg.Name {
+			return database.User{}, errUnique("users_name_key")
+		}
+	}
+
+	user := database.User{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+func (s *Store) GetUser(_ context.Context, name string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Name == name {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *Store) GetUsers(_ context.Context) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []database.User
+	users = append(users, s.users...)
+	return users, nil
+}
+
+func (s *Store) SetUserTimezone(_ context.Context, arg database.SetUserTimezoneParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == arg.ID {
+			s.users[i].Timezone = arg.Timezone
+			s.users[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteAllUsers(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = nil
+	s.follows = nil
+	s.reads = nil
+	s.saved = nil
+	s.savedTags = nil
+	s.deleteFeeds(func(database.Feed) bool { return true })
+	return nil
+}
+
+func (s *Store) userByID(id uuid.UUID) (database.User, bool) {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return database.User{}, false
+}
+
+// Feeds
+
+func (s *Store) CreateFeed(_ context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.feeds {
+		if f.ID == arg.ID {
+			return database.Feed{}, errUnique("feeds_pkey")
+		}
+		if f.Url == arg.Url {
+			return database.Feed{}, errUnique("feeds_url_key")
+		}
+	}
+	if _, ok := s.userByID(arg.UserID); !ok {
+		return database.Feed{}, errForeignKey("feeds_user_id_fkey")
+	}
+
+	feed := database.Feed{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+		Url:       arg.Url,
+		UserID:    arg.UserID,
+	}
+	s.feeds = append(s.feeds, feed)
+	return feed, nil
+}
+
+func (s *Store) GetFeed(_ context.Context, id uuid.UUID) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f, ok := s.feedByID(id); ok {
+		return f, nil
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (s *Store) GetFeedByURL(_ context.Context, url string) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.feeds {
+		if f.Url == url {
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (s *Store) GetFeedsByName(_ context.Context, name string) ([]database.Feed, error) {
+	return s.filterFeeds(func(f database.Feed) bool { return f.Name == name }), nil
+}
+
+func (s *Store) GetUserFeeds(_ context.Context, userID uuid.UUID) ([]database.Feed, error) {
+	return s.filterFeeds(func(f database.Feed) bool { return f.UserID == userID }), nil
+}
+
+func (s *Store) GetAllFeeds(_ context.Context) ([]database.Feed, error) {
+	feeds := s.filterFeeds(func(database.Feed) bool { return true })
+	sort.SliceStable(feeds, func(i, j int) bool {
+		if feeds[i].Name != feeds[j].Name {
+			return feeds[i].Name < feeds[j].Name
+		}
+		return feeds[i].Url < feeds[j].Url
+	})
+	return feeds, nil
+}
+
+func (s *Store) GetFeedsWithUsers(_ context.Context) ([]database.GetFeedsWithUsersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetFeedsWithUsersRow
+	for _, f := range s.feeds {
+		u, _ := s.userByID(f.UserID)
+		rows = append(rows, database.GetFeedsWithUsersRow{
+			ID:               f.ID,
+			CreatedAt:        f.CreatedAt,
+			UpdatedAt:        f.UpdatedAt,
+			Name:             f.Name,
+			Url:              f.Url,
+			UserID:           f.UserID,
+			FetchFullArticle: f.FetchFullArticle,
+			UserName:         u.Name,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.After(rows[j].CreatedAt)
+	})
+	return rows, nil
+}
+
+func (s *Store) GetNextFeedToFetch(_ context.Context) (database.Feed, error) {
+	feeds := s.filterFeeds(func(database.Feed) bool { return true })
+	sortByLastFetched(feeds)
+	if len(feeds) == 0 {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return feeds[0], nil
+}
+
+func (s *Store) GetFeedsDueForFetch(_ context.Context, lastFetchedAt sql.NullTime) ([]database.Feed, error) {
+	feeds := s.filterFeeds(func(f database.Feed) bool { return isDue(f, lastFetchedAt) })
+	sortByLastFetched(feeds)
+	return feeds, nil
+}
+
+func (s *Store) CountFeedsDueForFetch(_ context.Context, lastFetchedAt sql.NullTime) (int64, error) {
+	feeds := s.filterFeeds(func(f database.Feed) bool { return isDue(f, lastFetchedAt) })
+	return int64(len(feeds)), nil
+}
+
+// isDue mirrors "last_fetched_at IS NULL OR last_fetched_at <= $1"
+func isDue(f database.Feed, cutoff sql.NullTime) bool {
+	if !f.LastFetchedAt.Valid {
+		return true
+	}
+	return cutoff.Valid && !f.LastFetchedAt.Time.After(cutoff.Time)
+}
+
+// sortByLastFetched mirrors "ORDER BY last_fetched_at ASC NULLS FIRST"
+func sortByLastFetched(feeds []database.Feed) {
+	sort.SliceStable(feeds, func(i, j int) bool {
+		a, b := feeds[i].LastFetchedAt, feeds[j].LastFetchedAt
+		if !a.Valid || !b.Valid {
+			return !a.Valid && b.Valid
+		}
+		return a.Time.Before(b.Time)
+	})
+}
+
+func (s *Store) MarkFeedFetched(_ context.Context, arg database.MarkFeedFetchedParams) error {
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.LastFetchedAt = arg.LastFetchedAt
+		f.UpdatedAt = arg.LastFetchedAt.Time
+	})
+	return nil
+}
+
+func (s *Store) SetFeedFetchFullArticle(_ context.Context, arg database.SetFeedFetchFullArticleParams) error {
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.FetchFullArticle = arg.FetchFullArticle
+		f.UpdatedAt = arg.UpdatedAt
+	})
+	return nil
+}
+
+func (s *Store) SetFeedRetention(_ context.Context, arg database.SetFeedRetentionParams) error {
+	s.updateFeed(arg.ID, func(f *database.Feed) {
+		f.RetentionDays = arg.RetentionDays
+		f.RetentionKeep = arg.RetentionKeep
+		f.UpdatedAt = arg.UpdatedAt
+	})
+	return nil
+}
+
+func (s *Store) DeleteFeed(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteFeeds(func(f database.Feed) bool { return f.ID == id })
+	return nil
+}
+
+func (s *Store) DeleteUserFeeds(_ context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteFeeds(func(f database.Feed) bool { return f.UserID == userID })
+	return nil
+}
+
+func (s *Store) feedByID(id uuid.UUID) (database.Feed, bool) {
+	for _, f := range s.feeds {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return database.Feed{}, false
+}
+
+func (s *Store) filterFeeds(keep func(database.Feed) bool) []database.Feed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var feeds []database.Feed
+	for _, f := range s.feeds {
+		if keep(f) {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
+}
+
+func (s *Store) updateFeed(id uuid.UUID, update func(*database.Feed)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.feeds {
+		if s.feeds[i].ID == id {
+			update(&s.feeds[i])
+		}
+	}
+}
+
+// deleteFeeds removes the matching feeds along with everything that
+// references them. The caller must hold s.mu.
+func (s *Store) deleteFeeds(match func(database.Feed) bool) {
+	deleted := make(map[uuid.UUID]bool)
+	s.feeds = remove(s.feeds, func(f database.Feed) bool {
+		if match(f) {
+			deleted[f.ID] = true
+			return true
+		}
+		return false
+	})
+
+	s.follows = remove(s.follows, func(ff database.FeedFollow) bool { return deleted[ff.FeedID] })
+	s.fetches = remove(s.fetches, func(ff database.FeedFetch) bool { return deleted[ff.FeedID] })
+	s.deletePosts(func(p database.Post) bool { return deleted[p.FeedID] })
+}
+
+// Follows
+
+func (s *Store) CreateFeedFollow(_ context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ff := range s.follows {
+		if ff.ID == arg.ID {
+			return database.CreateFeedFollowRow{}, errUnique("feed_follows_pkey")
+		}
+		if ff.UserID == arg.UserID && ff.FeedID == arg.FeedID {
+			return database.CreateFeedFollowRow{}, errUnique("feed_follows_user_id_feed_id_key")
+		}
+	}
+	user, ok := s.userByID(arg.UserID)
+	if !ok {
+		return database.CreateFeedFollowRow{}, errForeignKey("feed_follows_user_id_fkey")
+	}
+	feed, ok := s.feedByID(arg.FeedID)
+	if !ok {
+		return database.CreateFeedFollowRow{}, errForeignKey("feed_follows_feed_id_fkey")
+	}
+
+	s.follows = append(s.follows, database.FeedFollow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+	})
+	return database.CreateFeedFollowRow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		UserName:  user.Name,
+		FeedName:  feed.Name,
+	}, nil
+}
+
+func (s *Store) GetFeedFollowsForUser(_ context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetFeedFollowsForUserRow
+	for _, ff := range s.follows {
+		if ff.UserID != userID {
+			continue
+		}
+		user, _ := s.userByID(ff.UserID)
+		feed, _ := s.feedByID(ff.FeedID)
+		rows = append(rows, database.GetFeedFollowsForUserRow{
+			ID:        ff.ID,
+			CreatedAt: ff.CreatedAt,
+			UpdatedAt: ff.UpdatedAt,
+			UserID:    ff.UserID,
+			FeedID:    ff.FeedID,
+			UserName:  user.Name,
+			FeedName:  feed.Name,
+			FeedUrl:   feed.Url,
+		})
+		for _, p := range s.posts {
+			if p.FeedID == ff.FeedID && !s.isRead(ff.UserID, p.ID) {
+				rows[len(rows)-1].UnreadCount++
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.After(rows[j].CreatedAt)
+	})
+	return rows, nil
+}
+
+func (s *Store) GetFeedFollowByUserAndFeed(_ context.Context, arg database.GetFeedFollowByUserAndFeedParams) (database.FeedFollow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ff := range s.follows {
+		if ff.UserID == arg.UserID && ff.FeedID == arg.FeedID {
+			return ff, nil
+		}
+	}
+	return database.FeedFollow{}, sql.ErrNoRows
+}
+
+func (s *Store) DeleteFeedFollow(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.follows = remove(s.follows, func(ff database.FeedFollow) bool { return ff.ID == id })
+	return nil
+}
+
+func (s *Store) DeleteFeedFollowByUserAndFeedURL(_ context.Context, arg database.DeleteFeedFollowByUserAndFeedURLParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.follows = remove(s.follows, func(ff database.FeedFollow) bool {
+		feed, _ := s.feedByID(ff.FeedID)
+		return ff.UserID == arg.UserID && feed.Url == arg.Url
+	})
+	return nil
+}
+
+// Posts
+
+func (s *Store) CreatePost(_ context.Context, arg database.CreatePostParams) (database.CreatePostRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.posts {
+		if p.ID == arg.ID {
+			return database.CreatePostRow{}, errUnique("posts_pkey")
+		}
+		if p.Url == arg.Url {
+			return database.CreatePostRow{}, errUnique("posts_url_key")
+		}
+	}
+	if _, ok := s.feedByID(arg.FeedID); !ok {
+		return database.CreatePostRow{}, errForeignKey("posts_feed_id_fkey")
+	}
+
+	post := database.Post{
+		ID:                  arg.ID,
+		CreatedAt:           arg.CreatedAt,
+		UpdatedAt:           arg.UpdatedAt,
+		Title:               arg.Title,
+		Url:                 arg.Url,
+		Description:         arg.Description,
+		PublishedAt:         arg.PublishedAt,
+		FeedID:              arg.FeedID,
+		PublishedAtInferred: arg.PublishedAtInferred,
+	}
+	s.posts = append(s.posts, post)
+	return database.CreatePostRow(postRow(post)), nil
+}
+
+func (s *Store) GetPostByURL(_ context.Context, url string) (database.GetPostByURLRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.posts {
+		if p.Url == url {
+			return postRow(p), nil
+		}
+	}
+	return database.GetPostByURLRow{}, sql.ErrNoRows
+}
+
+func (s *Store) GetPostsByIDPrefix(_ context.Context, arg database.GetPostsByIDPrefixParams) ([]database.GetPostsByIDPrefixRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	followed := make(map[uuid.UUID]bool)
+	for _, ff := range s.follows {
+		if ff.UserID == arg.UserID {
+			followed[ff.FeedID] = true
+		}
+	}
+
+	var posts []database.GetPostsByIDPrefixRow
+	for _, p := range s.posts {
+		if followed[p.FeedID] && bytes.Compare(p.ID[:], arg.Lower[:]) >= 0 && bytes.Compare(p.ID[:], arg.Upper[:]) <= 0 {
+			posts = append(posts, database.GetPostsByIDPrefixRow(postRow(p)))
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return bytes.Compare(posts[i].ID[:], posts[j].ID[:]) < 0 })
+	return limit(posts, 2), nil
+}
+
+// postRow returns the columns of p that the queries return, which leave
+// out the search column
+func postRow(p database.Post) database.GetPostByURLRow {
+	return database.GetPostByURLRow{
+		ID:                  p.ID,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+		Title:               p.Title,
+		Url:                 p.Url,
+		Description:         p.Description,
+		PublishedAt:         p.PublishedAt,
+		FeedID:              p.FeedID,
+		Content:             p.Content,
+		PublishedAtInferred: p.PublishedAtInferred,
+	}
+}
+
+func (s *Store) GetPostsCount(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.posts)), nil
+}
+
+func (s *Store) GetPostsForUser(_ context.Context, arg database.GetPostsForUserParams) ([]database.GetPostsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	followed := make(map[uuid.UUID]bool)
+	for _, ff := range s.follows {
+		if ff.UserID == arg.UserID {
+			followed[ff.FeedID] = true
+		}
+	}
+
+	var rows []database.GetPostsForUserRow
+	for _, p := range s.posts {
+		if !followed[p.FeedID] {
+			continue
+		}
+
+		read := s.isRead(arg.UserID, p.ID)
+		if arg.UnreadOnly && read {
+			continue
+		}
+
+		categories := s.postCategoryNames(p.ID)
+		authors := s.postAuthorList(p.ID)
+		if arg.Tag.Valid && !containsString(categories, strings.ToLower(arg.Tag.String)) {
+			continue
+		}
+		if arg.Author.Valid && !hasAuthor(authors, arg.Author.String) {
+			continue
+		}
+		if arg.FeedID.Valid && p.FeedID != arg.FeedID.UUID {
+			continue
+		}
+		// NULL dates never match a bound, as in SQL
+		if arg.Since.Valid && (!p.PublishedAt.Valid || p.PublishedAt.Time.Before(arg.Since.Time)) {
+			continue
+		}
+		if arg.Until.Valid && (!p.PublishedAt.Valid || !p.PublishedAt.Time.Before(arg.Until.Time)) {
+			continue
+		}
+		if arg.SavedOnly && !s.isSavedBy(arg.UserID, p.ID) {
+			continue
+		}
+		if arg.BeforeID.Valid && !browsesBefore(arg.BeforePublishedAt, arg.BeforeID.UUID, p.PublishedAt, p.ID) {
+			continue
+		}
+
+		authorNames := make([]string, len(authors))
+		for i, a := range authors {
+			authorNames[i] = a.Name
+		}
+
+		feed, _ := s.feedByID(p.FeedID)
+		rows = append(rows, database.GetPostsForUserRow{
+			ID:                  p.ID,
+			CreatedAt:           p.CreatedAt,
+			UpdatedAt:           p.UpdatedAt,
+			Title:               p.Title,
+			Url:                 p.Url,
+			Description:         p.Description,
+			Content:             p.Content,
+			PublishedAt:         p.PublishedAt,
+			PublishedAtInferred: p.PublishedAtInferred,
+			FeedID:              p.FeedID,
+			FeedName:            feed.Name,
+			Categories:          strings.Join(categories, ", "),
+			Authors:             strings.Join(authorNames, ", "),
+			Read:                read,
+		})
+	}
+
+	// ORDER BY feed name or created_at DESC when sorting by them, then
+	// published_at DESC NULLS FIRST, id DESC
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if arg.Sort == "feed" && a.FeedName != b.FeedName {
+			return a.FeedName < b.FeedName
+		}
+		if arg.Sort == "fetched" && !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return browsesBefore(a.PublishedAt, a.ID, b.PublishedAt, b.ID)
+	})
+	if int(arg.Offset) >= len(rows) {
+		return nil, nil
+	}
+	return limit(rows[arg.Offset:], arg.Limit), nil
+}
+
+// browsesBefore reports whether the post published at a with ID aID comes
+// before the one published at b with ID bID in browse's order
+func browsesBefore(a sql.NullTime, aID uuid.UUID, b sql.NullTime, bID uuid.UUID) bool {
+	if newerFirst(a, b) {
+		return true
+	}
+	if newerFirst(b, a) {
+		return false
+	}
+	return aID.String() > bID.String()
+}
+
+// newerFirst mirrors "ORDER BY published_at DESC NULLS FIRST"
+func newerFirst(a, b sql.NullTime) bool {
+	if !a.Valid || !b.Valid {
+		return !a.Valid && b.Valid
+	}
+	return a.Time.After(b.Time)
+}
+
+func (s *Store) CountNewerPosts(_ context.Context, arg database.CountNewerPostsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, p := range s.posts {
+		if p.FeedID != arg.FeedID {
+			continue
+		}
+		// NULL > x is never true, so a missing cutoff only matches NULL dates
+		if !p.PublishedAt.Valid || (arg.PublishedAt.Valid && p.PublishedAt.Time.After(arg.PublishedAt.Time)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) CountExpiredPosts(_ context.Context, arg database.CountExpiredPostsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := s.expiredPosts(arg.FeedID, arg.Cutoff, arg.Keep)
+	return int64(len(expired)), nil
+}
+
+func (s *Store) DeleteExpiredPosts(_ context.Context, arg database.DeleteExpiredPostsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := s.expiredPosts(arg.FeedID, arg.Cutoff, arg.Keep)
+	s.deletePosts(func(p database.Post) bool { return expired[p.ID] })
+	return int64(len(expired)), nil
+}
+
+// expiredPosts returns the IDs of the feed's posts published before cutoff or
+// beyond its keep newest, other than saved ones. The caller must hold s.mu.
+func (s *Store) expiredPosts(feedID uuid.UUID, cutoff sql.NullTime, keep sql.NullInt32) map[uuid.UUID]bool {
+	var posts []database.Post
+	for _, p := range s.posts {
+		if p.FeedID == feedID {
+			posts = append(posts, p)
+		}
+	}
+	// Ranked as browse shows them, so that the posts kept are the newest
+	// the user sees
+	sort.Slice(posts, func(i, j int) bool {
+		return browsesBefore(posts[i].PublishedAt, posts[i].ID, posts[j].PublishedAt, posts[j].ID)
+	})
+
+	expired := make(map[uuid.UUID]bool)
+	for i, p := range posts {
+		old := cutoff.Valid && p.PublishedAt.Valid && p.PublishedAt.Time.Before(cutoff.Time)
+		surplus := keep.Valid && i >= int(keep.Int32)
+		if (old || surplus) && !s.isSaved(p.ID) {
+			expired[p.ID] = true
+		}
+	}
+	return expired
+}
+
+func (s *Store) UpdatePostContent(_ context.Context, arg database.UpdatePostContentParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == arg.ID {
+			s.posts[i].Content = arg.Content
+			s.posts[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+// SearchPosts matches whole words, where Postgres also matches other forms
+// of them ("fetching" finds "fetched"). Posts are ranked by how often they
+// contain the search terms, with the title counting most.
+func (s *Store) SearchPosts(_ context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := websearch.Parse(arg.Query)
+
+	followed := make(map[uuid.UUID]bool)
+	for _, ff := range s.follows {
+		if ff.UserID == arg.UserID {
+			followed[ff.FeedID] = true
+		}
+	}
+
+	type match struct {
+		row  database.SearchPostsRow
+		rank float64
+	}
+	var matches []match
+	for _, p := range s.posts {
+		if !followed[p.FeedID] || (arg.FeedID.Valid && p.FeedID != arg.FeedID.UUID) {
+			continue
+		}
+		if arg.Since.Valid && (!p.PublishedAt.Valid || p.PublishedAt.Time.Before(arg.Since.Time)) {
+			continue
+		}
+		if arg.Until.Valid && (!p.PublishedAt.Valid || !p.PublishedAt.Time.Before(arg.Until.Time)) {
+			continue
+		}
+
+		// Markup isn't searched, as Postgres's parser skips tags
+		description := htmltext.Render(p.Description.String).Text
+		content := htmltext.Render(p.Content.String).Text
+
+		// Weighted like the A, B and C weights of the tsvector column
+		fields := []struct {
+			words  []string
+			weight float64
+		}{
+			{websearch.Words(p.Title), 1.0},
+			{websearch.Words(description), 0.4},
+			{websearch.Words(content), 0.2},
+		}
+
+		var terms []websearch.Term
+		for _, group := range query {
+			ok := true
+			for _, term := range group {
+				found := false
+				for _, f := range fields {
+					if countPhrase(f.words, term.Words) > 0 {
+						found = true
+						break
+					}
+				}
+				if found == term.Not {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				terms = group
+				break
+			}
+		}
+		if terms == nil {
+			continue
+		}
+
+		var rank float64
+		for _, term := range terms {
+			if term.Not {
+				continue
+			}
+			for _, f := range fields {
+				rank += f.weight * float64(countPhrase(f.words, term.Words))
+			}
+		}
+
+		source := p.Title
+		switch {
+		case p.Content.Valid:
+			source = content
+		case p.Description.Valid:
+			source = description
+		}
+
+		feed, _ := s.feedByID(p.FeedID)
+		matches = append(matches, match{
+			row: database.SearchPostsRow{
+				ID:                  p.ID,
+				Title:               p.Title,
+				Url:                 p.Url,
+				PublishedAt:         p.PublishedAt,
+				PublishedAtInferred: p.PublishedAtInferred,
+				FeedName:            feed.Name,
+				Snippet:             snippet(source, terms),
+			},
+			rank: rank,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank > matches[j].rank
+		}
+		return newerFirst(matches[i].row.PublishedAt, matches[j].row.PublishedAt)
+	})
+
+	rows := make([]database.SearchPostsRow, len(matches))
+	for i, m := range matches {
+		rows[i] = m.row
+	}
+	return limit(rows, arg.Limit), nil
+}
+
+// countPhrase counts the places words appear in order in text
+func countPhrase(text, words []string) int {
+	n := 0
+	for i := 0; i+len(words) <= len(text); i++ {
+		matched := true
+		for j, w := range words {
+			if text[i+j] != w {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			n++
+		}
+	}
+	return n
+}
+
+// snippet returns about twenty words of text around the first word of
+// terms it contains, with those words highlighted
+func snippet(text string, terms []websearch.Term) string {
+	wanted := make(map[string]bool)
+	for _, term := range terms {
+		if !term.Not {
+			for _, w := range term.Words {
+				wanted[w] = true
+			}
+		}
+	}
+
+	tokens := strings.Fields(text)
+	first := -1
+	for i, token := range tokens {
+		for _, w := range websearch.Words(token) {
+			if !wanted[w] {
+				continue
+			}
+			// Mark just the word, leaving out punctuation
+			lower := strings.ToLower(token)
+			if at := strings.Index(lower, w); at >= 0 && len(lower) == len(token) {
+				tokens[i] = token[:at] + websearch.HighlightStart + token[at:at+len(w)] + websearch.HighlightStop + token[at+len(w):]
+			}
+			if first < 0 {
+				first = i
+			}
+			break
+		}
+	}
+
+	start := max(first-8, 0)
+	end := min(start+20, len(tokens))
+	result := strings.Join(tokens[start:end], " ")
+	if start > 0 {
+		result = "… " + result
+	}
+	if end < len(tokens) {
+		result += " …"
+	}
+	return result
+}
+
+func (s *Store) DeletePostsByFeedID(_ context.Context, feedID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePosts(func(p database.Post) bool { return p.FeedID == feedID && !s.isSaved(p.ID) })
+	return nil
+}
+
+func (s *Store) postExists(id uuid.UUID) bool {
+	for _, p := range s.posts {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// deletePosts removes the matching posts along with their category and
+// author links. The caller must hold s.mu.
+func (s *Store) deletePosts(match func(database.Post) bool) {
+	deleted := make(map[uuid.UUID]bool)
+	s.posts = remove(s.posts, func(p database.Post) bool {
+		if match(p) {
+			deleted[p.ID] = true
+			return true
+		}
+		return false
+	})
+
+	s.postCategories = remove(s.postCategories, func(pc database.PostCategory) bool { return deleted[pc.PostID] })
+	s.postAuthors = remove(s.postAuthors, func(pa database.PostAuthor) bool { return deleted[pa.PostID] })
+	s.reads = remove(s.reads, func(pr database.PostRead) bool { return deleted[pr.PostID] })
+}
+
+// Reads
+
+func (s *Store) MarkPostRead(_ context.Context, arg database.MarkPostReadParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userByID(arg.UserID); !ok {
+		return errForeignKey("post_reads_user_id_fkey")
+	}
+	if !s.postExists(arg.PostID) {
+		return errForeignKey("post_reads_post_id_fkey")
+	}
+	if !s.isRead(arg.UserID, arg.PostID) {
+		s.reads = append(s.reads, database.PostRead{UserID: arg.UserID, PostID: arg.PostID, ReadAt: arg.ReadAt})
+	}
+	return nil
+}
+
+func (s *Store) MarkPostUnread(_ context.Context, arg database.MarkPostUnreadParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reads = remove(s.reads, func(pr database.PostRead) bool {
+		return pr.UserID == arg.UserID && pr.PostID == arg.PostID
+	})
+	return nil
+}
+
+func (s *Store) MarkPostsRead(_ context.Context, arg database.MarkPostsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	followed := make(map[uuid.UUID]bool)
+	for _, ff := range s.follows {
+		if ff.UserID == arg.UserID {
+			followed[ff.FeedID] = true
+		}
+	}
+
+	var n int64
+	for _, p := range s.posts {
+		if !followed[p.FeedID] || (arg.FeedID.Valid && p.FeedID != arg.FeedID.UUID) {
+			continue
+		}
+		if arg.Before.Valid && (!p.PublishedAt.Valid || !p.PublishedAt.Time.Before(arg.Before.Time)) {
+			continue
+		}
+		if !s.isRead(arg.UserID, p.ID) {
+			s.reads = append(s.reads, database.PostRead{UserID: arg.UserID, PostID: p.ID, ReadAt: arg.ReadAt})
+			n++
+		}
+	}
+	return n, nil
+}
+
+// isRead reports whether userID has read postID. The caller must hold s.mu.
+func (s *Store) isRead(userID, postID uuid.UUID) bool {
+	for _, pr := range s.reads {
+		if pr.UserID == userID && pr.PostID == postID {
+			return true
+		}
+	}
+	return false
+}
+
+// Saved posts
+
+func (s *Store) CreateSavedPost(_ context.Context, arg database.CreateSavedPostParams) (database.SavedPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var post database.Post
+	found := false
+	for _, p := range s.posts {
+		if p.ID == arg.PostID {
+			post, found = p, true
+			break
+		}
+	}
+	if !found {
+		return database.SavedPost{}, sql.ErrNoRows
+	}
+	feed, ok := s.feedByID(post.FeedID)
+	if !ok {
+		return database.SavedPost{}, sql.ErrNoRows
+	}
+	if _, ok := s.userByID(arg.UserID); !ok {
+		return database.SavedPost{}, errForeignKey("saved_posts_user_id_fkey")
+	}
+	for _, sp := range s.saved {
+		if sp.ID == arg.ID {
+			return database.SavedPost{}, errUnique("saved_posts_pkey")
+		}
+		if sp.UserID == arg.UserID && sp.PostID == arg.PostID {
+			return database.SavedPost{}, errUnique("saved_posts_user_id_post_id_key")
+		}
+	}
+
+	saved := database.SavedPost{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		PostID:      post.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.CreatedAt,
+		Url:         post.Url,
+		Title:       post.Title,
+		FeedName:    feed.Name,
+		PublishedAt: post.PublishedAt,
+		Description: post.Description,
+		Content:     post.Content,
+		Note:        arg.Note,
+	}
+	s.saved = append(s.saved, saved)
+	return saved, nil
+}
+
+func (s *Store) GetSavedPost(_ context.Context, arg database.GetSavedPostParams) (database.SavedPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sp := range s.saved {
+		if sp.UserID == arg.UserID && sp.PostID == arg.PostID {
+			return sp, nil
+		}
+	}
+	return database.SavedPost{}, sql.ErrNoRows
+}
+
+func (s *Store) GetSavedPostByURL(_ context.Context, arg database.GetSavedPostByURLParams) (database.SavedPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sp := range s.saved {
+		if sp.UserID == arg.UserID && sp.Url == arg.Url {
+			return sp, nil
+		}
+	}
+	return database.SavedPost{}, sql.ErrNoRows
+}
+
+func (s *Store) GetSavedPostsByIDPrefix(_ context.Context, arg database.GetSavedPostsByIDPrefixParams) ([]database.SavedPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var saved []database.SavedPost
+	for _, sp := range s.saved {
+		if sp.UserID == arg.UserID && bytes.Compare(sp.PostID[:], arg.Lower[:]) >= 0 && bytes.Compare(sp.PostID[:], arg.Upper[:]) <= 0 {
+			saved = append(saved, sp)
+		}
+	}
+	sort.Slice(saved, func(i, j int) bool { return bytes.Compare(saved[i].PostID[:], saved[j].PostID[:]) < 0 })
+	return limit(saved, 2), nil
+}
+
+func (s *Store) SetSavedPostNote(_ context.Context, arg database.SetSavedPostNoteParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.saved {
+		if s.saved[i].ID == arg.ID {
+			s.saved[i].Note = arg.Note
+			s.saved[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteSavedPost(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saved = remove(s.saved, func(sp database.SavedPost) bool { return sp.ID == id })
+	s.savedTags = remove(s.savedTags, func(t database.SavedPostTag) bool { return t.SavedPostID == id })
+	return nil
+}
+
+func (s *Store) AddSavedPostTag(_ context.Context, arg database.AddSavedPostTagParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for _, sp := range s.saved {
+		if sp.ID == arg.SavedPostID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errForeignKey("saved_post_tags_saved_post_id_fkey")
+	}
+	for _, t := range s.savedTags {
+		if t == database.SavedPostTag(arg) {
+			return nil
+		}
+	}
+	s.savedTags = append(s.savedTags, database.SavedPostTag(arg))
+	return nil
+}
+
+func (s *Store) RemoveSavedPostTag(_ context.Context, arg database.RemoveSavedPostTagParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.savedTags = remove(s.savedTags, func(t database.SavedPostTag) bool { return t == database.SavedPostTag(arg) })
+	return nil
+}
+
+func (s *Store) GetSavedPosts(_ context.Context, arg database.GetSavedPostsParams) ([]database.GetSavedPostsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetSavedPostsRow
+	for _, sp := range s.saved {
+		if sp.UserID != arg.UserID {
+			continue
+		}
+		tags := s.savedPostTags(sp.ID)
+		if arg.Tag.Valid && !containsString(tags, arg.Tag.String) {
+			continue
+		}
+		if arg.FeedName.Valid && !strings.EqualFold(sp.FeedName, arg.FeedName.String) {
+			continue
+		}
+		if arg.Text.Valid {
+			text := strings.ToLower(arg.Text.String)
+			if !strings.Contains(strings.ToLower(sp.Title), text) && !strings.Contains(strings.ToLower(sp.Note), text) {
+				continue
+			}
+		}
+		if arg.Since.Valid && sp.CreatedAt.Before(arg.Since.Time) {
+			continue
+		}
+		if arg.Until.Valid && !sp.CreatedAt.Before(arg.Until.Time) {
+			continue
+		}
+
+		rows = append(rows, database.GetSavedPostsRow{
+			ID:          sp.ID,
+			UserID:      sp.UserID,
+			PostID:      sp.PostID,
+			CreatedAt:   sp.CreatedAt,
+			UpdatedAt:   sp.UpdatedAt,
+			Url:         sp.Url,
+			Title:       sp.Title,
+			FeedName:    sp.FeedName,
+			PublishedAt: sp.PublishedAt,
+			Description: sp.Description,
+			Content:     sp.Content,
+			Note:        sp.Note,
+			Tags:        strings.Join(tags, ", "),
+		})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].CreatedAt.After(rows[j].CreatedAt) })
+	return limit(rows, arg.Limit), nil
+}
+
+// savedPostTags returns a save's tags in order. The caller must hold s.mu.
+func (s *Store) savedPostTags(id uuid.UUID) []string {
+	var tags []string
+	for _, t := range s.savedTags {
+		if t.SavedPostID == id {
+			tags = append(tags, t.Tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// isSavedBy reports whether userID has saved postID. The caller must hold
+// s.mu.
+func (s *Store) isSavedBy(userID, postID uuid.UUID) bool {
+	for _, sp := range s.saved {
+		if sp.UserID == userID && sp.PostID == postID {
+			return true
+		}
+	}
+	return false
+}
+
+// isSaved reports whether any user has saved postID. The caller must hold
+// s.mu.
+func (s *Store) isSaved(postID uuid.UUID) bool {
+	for _, sp := range s.saved {
+		if sp.PostID == postID {
+			return true
+		}
+	}
+	return false
+}
+
+// Categories
+
+func (s *Store) UpsertCategory(_ context.Context, arg database.UpsertCategoryParams) (database.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.categories {
+		if c.Name == arg.Name {
+			return c, nil
+		}
+	}
+
+	category := database.Category{ID: arg.ID, CreatedAt: arg.CreatedAt, Name: arg.Name}
+	s.categories = append(s.categories, category)
+	return category, nil
+}
+
+func (s *Store) AddPostCategory(_ context.Context, arg database.AddPostCategoryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pc := range s.postCategories {
+		if pc.PostID == arg.PostID && pc.CategoryID == arg.CategoryID {
+			return nil
+		}
+	}
+	if !s.postExists(arg.PostID) {
+		return errForeignKey("post_categories_post_id_fkey")
+	}
+	if _, ok := s.categoryByID(arg.CategoryID); !ok {
+		return errForeignKey("post_categories_category_id_fkey")
+	}
+
+	s.postCategories = append(s.postCategories, database.PostCategory{
+		PostID:     arg.PostID,
+		CategoryID: arg.CategoryID,
+	})
+	return nil
+}
+
+func (s *Store) GetCategoriesForPost(_ context.Context, postID uuid.UUID) ([]database.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var categories []database.Category
+	for _, pc := range s.postCategories {
+		if pc.PostID != postID {
+			continue
+		}
+		if c, ok := s.categoryByID(pc.CategoryID); ok {
+			categories = append(categories, c)
+		}
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+	return categories, nil
+}
+
+func (s *Store) categoryByID(id uuid.UUID) (database.Category, bool) {
+	for _, c := range s.categories {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return database.Category{}, false
+}
+
+// postCategoryNames returns the sorted category names of a post. The caller
+// must hold s.mu.
+func (s *Store) postCategoryNames(postID uuid.UUID) []string {
+	var names []string
+	for _, pc := range s.postCategories {
+		if pc.PostID != postID {
+			continue
+		}
+		if c, ok := s.categoryByID(pc.CategoryID); ok {
+			names = append(names, c.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authors
+
+func (s *Store) UpsertAuthor(_ context.Context, arg database.UpsertAuthorParams) (database.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.authors {
+		if a.Name == arg.Name && a.Email == arg.Email {
+			return a, nil
+		}
+	}
+
+	author := database.Author{ID: arg.ID, CreatedAt: arg.CreatedAt, Name: arg.Name, Email: arg.Email}
+	s.authors = append(s.authors, author)
+	return author, nil
+}
+
+func (s *Store) AddPostAuthor(_ context.Context, arg database.AddPostAuthorParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pa := range s.postAuthors {
+		if pa.PostID == arg.PostID && pa.AuthorID == arg.AuthorID {
+			return nil
+		}
+	}
+	if !s.postExists(arg.PostID) {
+		return errForeignKey("post_authors_post_id_fkey")
+	}
+	if _, ok := s.authorByID(arg.AuthorID); !ok {
+		return errForeignKey("post_authors_author_id_fkey")
+	}
+
+	s.postAuthors = append(s.postAuthors, database.PostAuthor{
+		PostID:   arg.PostID,
+		AuthorID: arg.AuthorID,
+	})
+	return nil
+}
+
+func (s *Store) GetAuthorsForPost(_ context.Context, postID uuid.UUID) ([]database.Author, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.postAuthorList(postID), nil
+}
+
+func (s *Store) authorByID(id uuid.UUID) (database.Author, bool) {
+	for _, a := range s.authors {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return database.Author{}, false
+}
+
+// postAuthorList returns the authors of a post sorted by name. The caller
+// must hold s.mu.
+func (s *Store) postAuthorList(postID uuid.UUID) []database.Author {
+	var authors []database.Author
+	for _, pa := range s.postAuthors {
+		if pa.PostID != postID {
+			continue
+		}
+		if a, ok := s.authorByID(pa.AuthorID); ok {
+			authors = append(authors, a)
+		}
+	}
+	sort.SliceStable(authors, func(i, j int) bool {
+		return authors[i].Name < authors[j].Name
+	})
+	return authors
+}
+
+// hasAuthor reports whether any author's name or email matches ref, ignoring
+// case
+func hasAuthor(authors []database.Author, ref string) bool {
+	for _, a := range authors {
+		if strings.EqualFold(a.Name, ref) || strings.EqualFold(a.Email, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// Feed fetches
+
+func (s *Store) CreateFeedFetch(_ context.Context, arg database.CreateFeedFetchParams) (database.FeedFetch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ff := range s.fetches {
+		if ff.ID == arg.ID {
+			return database.FeedFetch{}, errUnique("feed_fetches_pkey")
+		}
+	}
+	if _, ok := s.feedByID(arg.FeedID); !ok {
+		return database.FeedFetch{}, errForeignKey("feed_fetches_feed_id_fkey")
+	}
+
+	fetch := database.FeedFetch{ID: arg.ID, FeedID: arg.FeedID, StartedAt: arg.StartedAt}
+	s.fetches = append(s.fetches, fetch)
+	return fetch, nil
+}
+
+func (s *Store) FinishFeedFetch(_ context.Context, arg database.FinishFeedFetchParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.fetches {
+		f := &s.fetches[i]
+		if f.ID != arg.ID {
+			continue
+		}
+		f.FinishedAt = arg.FinishedAt
+		f.HttpStatus = arg.HttpStatus
+		f.Bytes = arg.Bytes
+		f.ItemsSeen = arg.ItemsSeen
+		f.ItemsInserted = arg.ItemsInserted
+		f.Error = arg.Error
+	}
+	return nil
+}
+
+func (s *Store) GetFeedFetches(_ context.Context, arg database.GetFeedFetchesParams) ([]database.GetFeedFetchesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetFeedFetchesRow
+	for _, f := range s.fetches {
+		if arg.FeedID.Valid && f.FeedID != arg.FeedID.UUID {
+			continue
+		}
+		feed, _ := s.feedByID(f.FeedID)
+		rows = append(rows, database.GetFeedFetchesRow{
+			ID:            f.ID,
+			FeedID:        f.FeedID,
+			StartedAt:     f.StartedAt,
+			FinishedAt:    f.FinishedAt,
+			HttpStatus:    f.HttpStatus,
+			Bytes:         f.Bytes,
+			ItemsSeen:     f.ItemsSeen,
+			ItemsInserted: f.ItemsInserted,
+			Error:         f.Error,
+			FeedName:      feed.Name,
+			FeedUrl:       feed.Url,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].StartedAt.After(rows[j].StartedAt)
+	})
+	return limit(rows, arg.Limit), nil
+}
+
+// Helpers
+
+// remove returns items without the ones matching drop, reusing its backing
+// array
+func remove[T any](items []T, drop func(T) bool) []T {
+	kept := items[:0]
+	for _, item := range items {
+		if !drop(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// limit mirrors SQL's LIMIT n
+func limit[T any](rows []T, n int32) []T {
+	if n >= 0 && int(n) < len(rows) {
+		return rows[:n]
+	}
+	return rows
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
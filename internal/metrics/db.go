@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+)
+
+// DB wraps a database handle and records the latency of every query made
+// through it, labelled with the sqlc query name
+type DB struct {
+	db database.TxBeginner
+}
+
+// InstrumentDB returns db wrapped so that its queries, and those of the
+// transactions it begins, are timed
+func InstrumentDB(db database.TxBeginner) *DB {
+	return &DB{db: db}
+}
+
+func (d *DB) BeginTx(ctx context.Context) (database.Tx, error) {
+	tx, err := d.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer observe(query, time.Now())
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer observe(query, time.Now())
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer observe(query, time.Now())
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+// Tx times the queries of a transaction begun by DB.BeginTx
+type Tx struct {
+	database.Tx
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer observe(query, time.Now())
+	return t.Tx.ExecContext(ctx, query, args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer observe(query, time.Now())
+	return t.Tx.QueryContext(ctx, query, args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer observe(query, time.Now())
+	return t.Tx.QueryRowContext(ctx, query, args...)
+}
+
+func observe(query string, start time.Time) {
+	ObserveQuery(queryName(query), time.Since(start))
+}
+
+// queryName extracts the name from the "-- name: GetUser :one" comment sqlc
+// puts at the top of every query
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "other"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
@@ -0,0 +1,126 @@
+// Package metrics holds the Prometheus metrics exported by the aggregator.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	fetches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_feed_fetches_total",
+		Help: "Feed fetches by HTTP status code, or \"error\" when no response was received.",
+	}, []string{"status"})
+
+	fetchDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gator_feed_fetch_duration_seconds",
+		Help:    "Time taken to download and parse a feed.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	})
+
+	lastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gator_last_successful_fetch_timestamp_seconds",
+		Help: "Unix time of the last feed fetch that succeeded.",
+	})
+
+	postsInserted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_inserted_total",
+		Help: "Posts saved to the database.",
+	})
+
+	duplicateItems = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_duplicate_items_total",
+		Help: "Feed items skipped because their post already exists.",
+	})
+
+	postsPurged = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_purged_total",
+		Help: "Posts deleted by the retention policy.",
+	})
+
+	feedsDue = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gator_feeds_due",
+		Help: "Feeds not fetched within the aggregation interval, as of the last cycle.",
+	})
+
+	// TODO: set this once feeds can be disabled, e.g. after failing too
+	// often. Until then no feed ever is, and it is exported as 0 so that
+	// dashboards and alerts can already use it.
+	feedsDisabled = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gator_feeds_disabled",
+		Help: "Feeds disabled and no longer fetched. Always 0, as feeds can't be disabled yet.",
+	})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gator_db_query_duration_seconds",
+		Help:    "Database query latency by sqlc query name.",
+		Buckets: []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1},
+	}, []string{"query"})
+)
+
+func init() {
+	registry.MustRegister(
+		fetches,
+		fetchDuration,
+		lastSuccess,
+		postsInserted,
+		duplicateItems,
+		postsPurged,
+		feedsDue,
+		feedsDisabled,
+		queryDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// FetchFinished records a feed fetch. status is the HTTP status code, or 0
+// if the server never answered.
+func FetchFinished(status int, duration time.Duration, err error) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	fetches.WithLabelValues(label).Inc()
+	fetchDuration.Observe(duration.Seconds())
+	if err == nil {
+		lastSuccess.SetToCurrentTime()
+	}
+}
+
+// PostInserted counts a newly saved post
+func PostInserted() {
+	postsInserted.Inc()
+}
+
+// DuplicateItem counts a feed item whose post was already saved
+func DuplicateItem() {
+	duplicateItems.Inc()
+}
+
+// PostsPurged counts posts deleted by the retention policy
+func PostsPurged(n int) {
+	postsPurged.Add(float64(n))
+}
+
+// SetFeedsDue records how many feeds are waiting to be fetched
+func SetFeedsDue(n int) {
+	feedsDue.Set(float64(n))
+}
+
+// ObserveQuery records how long a database query took
+func ObserveQuery(query string, duration time.Duration) {
+	queryDuration.WithLabelValues(query).Observe(duration.Seconds())
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
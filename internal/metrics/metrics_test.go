@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerExportsMetrics(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/metrics = %d", rec.Code)
+	}
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE gator_feed_fetch_duration_seconds histogram",
+		"# TYPE gator_last_successful_fetch_timestamp_seconds gauge",
+		"# TYPE gator_posts_inserted_total counter",
+		"# TYPE gator_duplicate_items_total counter",
+		"# TYPE gator_posts_purged_total counter",
+		"# TYPE gator_feeds_due gauge",
+		"gator_feeds_disabled 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("/metrics has no line %q", line)
+		}
+	}
+}
@@ -0,0 +1,537 @@
+// Package migrate applies the versioned SQL migrations in sql/schema and
+// records them in a schema_migrations table.
+//
+// Migration files use goose's layout: a name such as "005_posts.sql" whose
+// numeric prefix is the version, and "-- +goose Up" / "-- +goose Down"
+// markers around the SQL for each direction.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// AppliedMigration is a row of schema_migrations
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// ErrDrift is returned when the migrations recorded in the database don't
+// match the migrations gator was built with
+var ErrDrift = errors.New("schema drift")
+
+// ErrLockTimeout is returned when another process holds the migration lock
+// for longer than Lock was willing to wait
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// lockKey identifies the migration lock among the database's advisory locks.
+// It spells "gator" in ASCII.
+const lockKey int64 = 0x6761746f72
+
+// lockPollInterval is how often Lock retries while another process holds
+// the lock
+const lockPollInterval = 500 * time.Millisecond
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+// Load reads every .sql migration in dir of fsys, sorted by version
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := parse(entry.Name(), string(content))
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[m.Version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), m.Version)
+		}
+		seen[m.Version] = entry.Name()
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func parse(filename, content string) (Migration, error) {
+	prefix, _, ok := strings.Cut(filename, "_")
+	if !ok {
+		return Migration{}, fmt.Errorf("migration %s: name must start with a version, e.g. 001_users.sql", filename)
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return Migration{}, fmt.Errorf("migration %s: invalid version %q", filename, prefix)
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	m := Migration{
+		Version:  version,
+		Name:     strings.TrimSuffix(filename, ".sql"),
+		Checksum: hex.EncodeToString(sum[:]),
+	}
+
+	var up, down strings.Builder
+	var current *strings.Builder
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case "-- +goose Up":
+			current = &up
+			continue
+		case "-- +goose Down":
+			current = &down
+			continue
+		case "-- +goose StatementBegin", "-- +goose StatementEnd":
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+		}
+	}
+
+	m.Up = strings.TrimSpace(up.String())
+	m.Down = strings.TrimSpace(down.String())
+	if m.Up == "" {
+		return Migration{}, fmt.Errorf("migration %s has no '-- +goose Up' section", filename)
+	}
+	return m, nil
+}
+
+// Dialect is the SQL flavour of the database being migrated
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+
+	// assumed, when not nil, is used in place of schema_migrations
+	assumed []AppliedMigration
+}
+
+// New returns a Migrator for db. migrations must be sorted by version, as
+// returned by Load.
+func New(db *sql.DB, dialect Dialect, migrations []Migration) *Migrator {
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}
+}
+
+// Lock takes a session-level Postgres advisory lock so that only one
+// process migrates the database at a time, waiting up to timeout for another
+// holder to finish. waiting, if not nil, is called once if the lock is
+// already taken. The returned function releases the lock.
+//
+// SQLite has no advisory locks. Its databases are local files for a single
+// user, so Lock does nothing for them.
+func (m *Migrator) Lock(ctx context.Context, timeout time.Duration, waiting func()) (func(), error) {
+	if m.dialect == SQLite {
+		return func() {}, nil
+	}
+
+	// Advisory locks belong to a session, so hold one connection for as
+	// long as the lock is held
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("take migration lock: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		var locked bool
+		err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, lockKey).Scan(&locked)
+		if err != nil {
+			conn.Close()
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("%w after %v", ErrLockTimeout, timeout)
+			}
+			return nil, fmt.Errorf("take migration lock: %w", err)
+		}
+		if locked {
+			break
+		}
+
+		if first && waiting != nil {
+			waiting()
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			conn.Close()
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("%w after %v", ErrLockTimeout, timeout)
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	unlock := func() {
+		// Closing the connection would release the lock as well, but
+		// database/sql may keep it open in the pool, so unlock explicitly
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		conn.Close()
+	}
+	return unlock, nil
+}
+
+// Initialized reports whether the schema_migrations table exists
+func (m *Migrator) Initialized(ctx context.Context) (bool, error) {
+	query := `SELECT EXISTS (
+        SELECT FROM information_schema.tables
+        WHERE table_schema = current_schema() AND table_name = 'schema_migrations'
+    )`
+	if m.dialect == SQLite {
+		query = `SELECT EXISTS (
+            SELECT 1 FROM sqlite_master
+            WHERE type = 'table' AND name = 'schema_migrations'
+        )`
+	}
+
+	var exists bool
+	err := m.db.QueryRowContext(ctx, query).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check schema_migrations table exists: %w", err)
+	}
+	return exists, nil
+}
+
+// Init creates the schema_migrations table if it doesn't exist yet and
+// reports whether it had to
+func (m *Migrator) Init(ctx context.Context) (bool, error) {
+	exists, err := m.Initialized(ctx)
+	if err != nil || exists {
+		return false, err
+	}
+
+	if _, err := m.db.ExecContext(ctx, createMigrationsTable); err != nil {
+		return false, fmt.Errorf("create schema_migrations table: %w", err)
+	}
+	return true, nil
+}
+
+// AssumeBaseline makes the Migrator read a database that has no
+// schema_migrations table as if Baseline(version) had been run on it, so that
+// Status, Pending and Reversible can preview an unversioned database without
+// writing to it. A version of 0 assumes nothing has been applied. Such a
+// Migrator must not be used to apply or revert migrations.
+func (m *Migrator) AssumeBaseline(version int64) {
+	m.assumed = []AppliedMigration{}
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			break
+		}
+		m.assumed = append(m.assumed, AppliedMigration{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum})
+	}
+}
+
+// Applied returns the migrations recorded in schema_migrations, oldest first
+func (m *Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	if m.assumed != nil {
+		return m.assumed, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+// Verify checks that every applied migration is one we know and hasn't been
+// edited since it ran, returning an error wrapping ErrDrift if not
+func (m *Migrator) Verify(ctx context.Context) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	return m.verify(applied)
+}
+
+func (m *Migrator) verify(applied []AppliedMigration) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	for _, a := range applied {
+		mig, ok := known[a.Version]
+		if !ok {
+			return fmt.Errorf("%w: database has migration %d (%s) which this version of gator doesn't know about", ErrDrift, a.Version, a.Name)
+		}
+		if mig.Checksum != a.Checksum {
+			return fmt.Errorf("%w: migration %d (%s) was changed after it was applied", ErrDrift, a.Version, mig.Name)
+		}
+	}
+	return nil
+}
+
+// Pending returns the migrations that haven't been applied yet, after
+// checking the applied ones for drift
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if !done[mig.Version] {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the next n pending migrations in order, or all of them if n is
+// 0, each in its own transaction. before is called with each migration just
+// before it runs.
+func (m *Migrator) Up(ctx context.Context, n int, before func(Migration)) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 && n < len(pending) {
+		pending = pending[:n]
+	}
+
+	for _, mig := range pending {
+		if before != nil {
+			before(mig)
+		}
+		if err := m.run(ctx, mig, mig.Up, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reversible returns the last n applied migrations, newest first, which is
+// the order Down reverts them in
+func (m *Migrator) Reversible(ctx context.Context, n int) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	var revert []Migration
+	for i := len(applied) - 1; i >= 0 && len(revert) < n; i-- {
+		mig := known[applied[i].Version]
+		if mig.Down == "" {
+			return nil, fmt.Errorf("migration %s has no '-- +goose Down' section and can't be reverted", mig.Name)
+		}
+		revert = append(revert, mig)
+	}
+	return revert, nil
+}
+
+// Down reverts the last n applied migrations, newest first, each in its own
+// transaction. before is called with each migration just before it's
+// reverted.
+func (m *Migrator) Down(ctx context.Context, n int, before func(Migration)) error {
+	revert, err := m.Reversible(ctx, n)
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range revert {
+		if before != nil {
+			before(mig)
+		}
+		if err := m.run(ctx, mig, mig.Down, forget); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) run(ctx context.Context, mig Migration, query string, bookkeep func(context.Context, *sql.Tx, Migration) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %s: %w", mig.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("migration %s: %w", mig.Name, err)
+	}
+	if err := bookkeep(ctx, tx, mig); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %s: %w", mig.Name, err)
+	}
+	return nil
+}
+
+// Status describes one migration and whether it has been applied
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Changed is set when the applied migration's checksum no longer
+	// matches the file
+	Changed bool
+	// Unknown is set for an applied migration gator doesn't have a file for
+	Unknown bool
+}
+
+// Status lists every known migration, plus any unknown applied ones, by
+// version. Unlike Pending it doesn't fail on drift, so that the drift can be
+// shown.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]AppliedMigration, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+
+	var statuses []Status
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if a, ok := byVersion[mig.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = a.AppliedAt
+			st.Changed = a.Checksum != mig.Checksum
+			delete(byVersion, mig.Version)
+		}
+		statuses = append(statuses, st)
+	}
+	for _, a := range byVersion {
+		statuses = append(statuses, Status{
+			Version:   a.Version,
+			Name:      a.Name,
+			Applied:   true,
+			AppliedAt: a.AppliedAt,
+			Unknown:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Baseline records every migration up to and including version as applied
+// without running it, for databases whose schema was created some other way
+func (m *Migrator) Baseline(ctx context.Context, version int64) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			break
+		}
+		if err := record(ctx, tx, mig); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// record marks mig as applied
+func record(ctx context.Context, tx *sql.Tx, mig Migration) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+		mig.Version, mig.Name, mig.Checksum, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("record migration %s: %w", mig.Name, err)
+	}
+	return nil
+}
+
+// forget marks mig as no longer applied
+func forget(ctx context.Context, tx *sql.Tx, mig Migration) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+		return fmt.Errorf("unrecord migration %s: %w", mig.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/AlexTLDR/gator/internal/htmltext"
+	"github.com/AlexTLDR/gator/internal/websearch"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+func init() {
+	// websearch_to_fts5 stands in for Postgres's websearch_to_tsquery in the
+	// SQLite queries, turning a search as typed into an FTS5 MATCH expression
+	sqlitedriver.MustRegisterDeterministicScalarFunction("websearch_to_fts5", 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		text, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("websearch_to_fts5: want text, got %T", args[0])
+		}
+		return ftsQuery(websearch.Parse(text)), nil
+	})
+
+	// html_text turns a post's HTML into the plain text the search index
+	// holds
+	sqlitedriver.MustRegisterDeterministicScalarFunction("html_text", 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return htmltext.Render(v).Text, nil
+		default:
+			return nil, fmt.Errorf("html_text: want text, got %T", args[0])
+		}
+	})
+}
+
+// ftsQuery writes query in FTS5's syntax. Every word is quoted, so nothing
+// the user types can be mistaken for an FTS5 operator. A query that can
+// match nothing becomes an empty phrase, which FTS5 matches against nothing.
+func ftsQuery(query websearch.Query) string {
+	var alternatives []string
+	for _, group := range query {
+		var include, exclude []string
+		for _, term := range group {
+			phrase := `"` + strings.Join(term.Words, " ") + `"`
+			if term.Not {
+				exclude = append(exclude, phrase)
+			} else {
+				include = append(include, phrase)
+			}
+		}
+
+		expr := "(" + strings.Join(include, " AND ") + ")"
+		if len(exclude) > 0 {
+			expr += " NOT (" + strings.Join(exclude, " OR ") + ")"
+		}
+		alternatives = append(alternatives, "("+expr+")")
+	}
+
+	if len(alternatives) == 0 {
+		return `""`
+	}
+	return strings.Join(alternatives, " OR ")
+}
@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/AlexTLDR/gator/internal/websearch"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"word", "postgres", `(("postgres"))`},
+		{"words", "pgvector index", `(("pgvector" AND "index"))`},
+		{"phrase", `"vector search"`, `(("vector search"))`},
+		{"unterminated quote", `postgres "vector search`, `(("postgres" AND "vector search"))`},
+		{"or", "postgres or sqlite", `(("postgres")) OR (("sqlite"))`},
+		{"excluded word", "postgres -mysql", `(("postgres") NOT ("mysql"))`},
+		{"excluded words", "postgres -mysql -oracle", `(("postgres") NOT ("mysql" OR "oracle"))`},
+		{"excluded phrase", `postgres -"vector search"`, `(("postgres") NOT ("vector search"))`},
+		{"exclusions stay in their alternative", "a -b or c", `(("a") NOT ("b")) OR (("c"))`},
+		{"FTS5 operators are quoted", "near AND not", `(("near" AND "and" AND "not"))`},
+		{"FTS5 syntax is dropped", `title:postgres* ^first`, `(("title postgres" AND "first"))`},
+		{"only excluded words match nothing", "-mysql -oracle", `""`},
+		{"empty matches nothing", "", `""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ftsQuery(websearch.Parse(tt.in)); got != tt.want {
+				t.Errorf("ftsQuery(Parse(%q)) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Package sqlite lets gator keep its data in a local SQLite file instead of
+// a Postgres server.
+//
+// The handlers talk to the database through the code sqlc generates for
+// Postgres. DB slots in underneath that code and swaps each query for the
+// SQLite version with the same "-- name:", kept in sql/sqlite/queries, so the
+// generated types and methods are shared by both backends.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the SQLite database at path, creating it if needed, with
+// foreign keys enforced so that deletes cascade as they do in Postgres
+func Open(path string) (*sql.DB, error) {
+	dsn := "file:" + path +
+		"?_pragma=foreign_keys(1)" +
+		"&_pragma=busy_timeout(5000)" +
+		"&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// DB runs sqlc queries against SQLite. It implements database.TxBeginner.
+type DB struct {
+	db      *sql.DB
+	queries map[string]string
+}
+
+// New returns a DB that runs the queries in the .sql files in dir of fsys
+// in place of the sqlc queries with the same names. A query without a
+// SQLite version is run as written.
+func New(db *sql.DB, fsys fs.FS, dir string) (*DB, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for name, query := range splitQueries(string(content)) {
+			if _, ok := queries[name]; ok {
+				return nil, fmt.Errorf("query %s is defined twice", name)
+			}
+			queries[name] = query
+		}
+	}
+
+	return &DB{db: db, queries: queries}, nil
+}
+
+// splitQueries splits a sqlc query file into its named queries
+func splitQueries(content string) map[string]string {
+	queries := make(map[string]string)
+	var name string
+	var query strings.Builder
+	flush := func() {
+		if name != "" {
+			queries[name] = strings.TrimSpace(query.String())
+		}
+		query.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if rest, ok := strings.CutPrefix(line, "-- name: "); ok {
+			flush()
+			name, _, _ = strings.Cut(rest, " ")
+		}
+		query.WriteString(line)
+	}
+	flush()
+	return queries
+}
+
+// translate returns the SQLite version of a sqlc query, and its arguments
+// with times in UTC. SQLite stores times as text, so they only compare
+// correctly when they share a zone.
+func (d *DB) translate(query string, args []interface{}) (string, []interface{}) {
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		name, _, _ := strings.Cut(rest, " ")
+		if q, ok := d.queries[name]; ok {
+			query = q
+		}
+	}
+
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case sql.NullTime:
+			v.Time = v.Time.UTC()
+			converted[i] = v
+		default:
+			converted[i] = arg
+		}
+	}
+	return query, converted
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = d.translate(query, args)
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	query, _ = d.translate(query, nil)
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = d.translate(query, args)
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = d.translate(query, args)
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx begins a transaction that runs its queries the way d does
+func (d *DB) BeginTx(ctx context.Context) (database.Tx, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, d: d}, nil
+}
+
+// Tx is a transaction begun by DB.BeginTx
+type Tx struct {
+	tx *sql.Tx
+	d  *DB
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = t.d.translate(query, args)
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+func (t *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	query, _ = t.d.translate(query, nil)
+	return t.tx.PrepareContext(ctx, query)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = t.d.translate(query, args)
+	return t.tx.QueryContext(ctx, query, args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = t.d.translate(query, args)
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,1294 @@
+// Package storetest is a behaviour test suite for database.Store
+// implementations. The in-memory store, SQLite and Postgres each run it, so
+// that they keep the same semantics as the queries change.
+package storetest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Opener returns an empty store for one test, registering any cleanup with
+// t
+type Opener func(t *testing.T) database.Store
+
+// Run runs every behaviour test against stores returned by open
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, e *env)
+	}{
+		{"Users", testUsers},
+		{"Feeds", testFeeds},
+		{"FeedScheduling", testFeedScheduling},
+		{"FeedFollows", testFeedFollows},
+		{"DeleteCascades", testDeleteCascades},
+		{"Posts", testPosts},
+		{"PostsByIDPrefix", testPostsByIDPrefix},
+		{"Transactions", testTransactions},
+		{"BrowseFilters", testBrowseFilters},
+		{"BrowseSorts", testBrowseSorts},
+		{"BrowsePaging", testBrowsePaging},
+		{"Reads", testReads},
+		{"SavedPosts", testSavedPosts},
+		{"SavedPostsOutliveTheirFeed", testSavedPostsOutliveTheirFeed},
+		{"Retention", testRetention},
+		{"RetentionKeepsWhatBrowseShows", testRetentionKeepsWhatBrowseShows},
+		{"Search", testSearch},
+		{"FeedFetches", testFeedFetches},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, &env{t: t, q: open(t), ctx: context.Background(), base: base})
+		})
+	}
+}
+
+// base is when every test's data starts. Times are whole seconds in UTC,
+// which every store keeps exactly.
+var base = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// env creates test data, failing the test on any error
+type env struct {
+	t    *testing.T
+	q    database.Store
+	ctx  context.Context
+	base time.Time
+	n    int
+}
+
+func (e *env) at(d time.Duration) time.Time {
+	return e.base.Add(d)
+}
+
+func (e *env) user(name string) database.User {
+	e.t.Helper()
+	u, err := e.q.CreateUser(e.ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: e.base,
+		UpdatedAt: e.base,
+		Name:      name,
+	})
+	if err != nil {
+		e.t.Fatalf("CreateUser(%q): %v", name, err)
+	}
+	return u
+}
+
+func (e *env) feed(owner database.User, name string) database.Feed {
+	e.t.Helper()
+	e.n++
+	f, err := e.q.CreateFeed(e.ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: e.at(time.Duration(e.n) * time.Second),
+		UpdatedAt: e.at(time.Duration(e.n) * time.Second),
+		Name:      name,
+		Url:       fmt.Sprintf("https://%s.example/feed.xml", name),
+		UserID:    owner.ID,
+	})
+	if err != nil {
+		e.t.Fatalf("CreateFeed(%q): %v", name, err)
+	}
+	return f
+}
+
+func (e *env) follow(u database.User, f database.Feed) {
+	e.t.Helper()
+	e.n++
+	_, err := e.q.CreateFeedFollow(e.ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: e.at(time.Duration(e.n) * time.Second),
+		UpdatedAt: e.at(time.Duration(e.n) * time.Second),
+		UserID:    u.ID,
+		FeedID:    f.ID,
+	})
+	if err != nil {
+		e.t.Fatalf("CreateFeedFollow(%s, %s): %v", u.Name, f.Name, err)
+	}
+}
+
+// post stores a post published at the given offset from base, or with no
+// date if published is nil. Posts are stored a second apart in the order
+// they're created.
+func (e *env) post(f database.Feed, title string, published *time.Duration) database.CreatePostRow {
+	e.t.Helper()
+	return e.postWithID(uuid.New(), f, title, published)
+}
+
+func (e *env) postWithID(id uuid.UUID, f database.Feed, title string, published *time.Duration) database.CreatePostRow {
+	e.t.Helper()
+	e.n++
+	var pub sql.NullTime
+	if published != nil {
+		pub = sql.NullTime{Time: e.at(*published), Valid: true}
+	}
+	p, err := e.q.CreatePost(e.ctx, database.CreatePostParams{
+		ID:          id,
+		CreatedAt:   e.at(time.Duration(e.n) * time.Second),
+		UpdatedAt:   e.at(time.Duration(e.n) * time.Second),
+		Title:       title,
+		Url:         fmt.Sprintf("https://%s.example/%s", f.Name, id),
+		Description: sql.NullString{String: title, Valid: true},
+		PublishedAt: pub,
+		FeedID:      f.ID,
+	})
+	if err != nil {
+		e.t.Fatalf("CreatePost(%q): %v", title, err)
+	}
+	return p
+}
+
+func (e *env) tag(p database.CreatePostRow, name string) {
+	e.t.Helper()
+	c, err := e.q.UpsertCategory(e.ctx, database.UpsertCategoryParams{ID: uuid.New(), CreatedAt: e.base, Name: name})
+	if err != nil {
+		e.t.Fatalf("UpsertCategory(%q): %v", name, err)
+	}
+	if err := e.q.AddPostCategory(e.ctx, database.AddPostCategoryParams{PostID: p.ID, CategoryID: c.ID}); err != nil {
+		e.t.Fatalf("AddPostCategory(%q): %v", name, err)
+	}
+}
+
+func (e *env) author(p database.CreatePostRow, name, email string) {
+	e.t.Helper()
+	a, err := e.q.UpsertAuthor(e.ctx, database.UpsertAuthorParams{ID: uuid.New(), CreatedAt: e.base, Name: name, Email: email})
+	if err != nil {
+		e.t.Fatalf("UpsertAuthor(%q): %v", name, err)
+	}
+	if err := e.q.AddPostAuthor(e.ctx, database.AddPostAuthorParams{PostID: p.ID, AuthorID: a.ID}); err != nil {
+		e.t.Fatalf("AddPostAuthor(%q): %v", name, err)
+	}
+}
+
+func (e *env) read(u database.User, p database.CreatePostRow) {
+	e.t.Helper()
+	if err := e.q.MarkPostRead(e.ctx, database.MarkPostReadParams{UserID: u.ID, PostID: p.ID, ReadAt: e.base}); err != nil {
+		e.t.Fatalf("MarkPostRead(%q): %v", p.Title, err)
+	}
+}
+
+func (e *env) save(u database.User, p database.CreatePostRow, savedAt time.Duration, note string) database.SavedPost {
+	e.t.Helper()
+	s, err := e.q.CreateSavedPost(e.ctx, database.CreateSavedPostParams{
+		ID:        uuid.New(),
+		UserID:    u.ID,
+		CreatedAt: e.at(savedAt),
+		Note:      note,
+		PostID:    p.ID,
+	})
+	if err != nil {
+		e.t.Fatalf("CreateSavedPost(%q): %v", p.Title, err)
+	}
+	return s
+}
+
+// browse returns the titles GetPostsForUser returns for params, with the
+// sort defaulting to published and the limit to 100
+func (e *env) browse(params database.GetPostsForUserParams) []string {
+	e.t.Helper()
+	if params.Sort == "" {
+		params.Sort = "published"
+	}
+	if params.Limit == 0 {
+		params.Limit = 100
+	}
+	rows, err := e.q.GetPostsForUser(e.ctx, params)
+	if err != nil {
+		e.t.Fatalf("GetPostsForUser: %v", err)
+	}
+	titles := []string{}
+	for _, r := range rows {
+		titles = append(titles, r.Title)
+	}
+	return titles
+}
+
+// idRange returns the smallest and largest IDs starting with hex, which is
+// whole hex digits without dashes
+func idRange(hex string) (lower, upper uuid.UUID) {
+	return uuid.MustParse(hex + strings.Repeat("0", 32-len(hex))), uuid.MustParse(hex + strings.Repeat("f", 32-len(hex)))
+}
+
+func ago(d time.Duration) *time.Duration {
+	return &d
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameSet reports whether a and b hold the same strings in any order
+func sameSet(a, b []string) bool {
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return equal(a, b)
+}
+
+func testUsers(t *testing.T, e *env) {
+	alice := e.user("alice")
+	e.user("bob")
+
+	got, err := e.q.GetUser(e.ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got.ID != alice.ID || !got.CreatedAt.Equal(e.base) || got.Timezone != "" {
+		t.Errorf("GetUser = %+v, want %+v", got, alice)
+	}
+
+	if _, err := e.q.GetUser(e.ctx, "carol"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser of a missing user: %v, want sql.ErrNoRows", err)
+	}
+
+	_, err = e.q.CreateUser(e.ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Name: "alice"})
+	if err == nil {
+		t.Error("CreateUser with a taken name succeeded")
+	}
+
+	users, err := e.q.GetUsers(e.ctx)
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	if !sameSet(names, []string{"alice", "bob"}) {
+		t.Errorf("GetUsers = %v, want alice and bob", names)
+	}
+
+	err = e.q.SetUserTimezone(e.ctx, database.SetUserTimezoneParams{Timezone: "Europe/Berlin", UpdatedAt: e.at(time.Hour), ID: alice.ID})
+	if err != nil {
+		t.Fatalf("SetUserTimezone: %v", err)
+	}
+	got, _ = e.q.GetUser(e.ctx, "alice")
+	if got.Timezone != "Europe/Berlin" || !got.UpdatedAt.Equal(e.at(time.Hour)) {
+		t.Errorf("after SetUserTimezone GetUser = %+v", got)
+	}
+}
+
+func testFeeds(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	e.feed(alice, "blog")
+
+	got, err := e.q.GetFeedByURL(e.ctx, news.Url)
+	if err != nil || got.ID != news.ID || got.LastFetchedAt.Valid || got.FetchFullArticle {
+		t.Errorf("GetFeedByURL = %+v, %v", got, err)
+	}
+	if _, err := e.q.GetFeedByURL(e.ctx, "https://missing.example/"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedByURL of a missing feed: %v, want sql.ErrNoRows", err)
+	}
+	if got, err := e.q.GetFeed(e.ctx, news.ID); err != nil || got.Url != news.Url {
+		t.Errorf("GetFeed = %+v, %v", got, err)
+	}
+
+	_, err = e.q.CreateFeed(e.ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Name: "again", Url: news.Url, UserID: alice.ID,
+	})
+	if err == nil {
+		t.Error("CreateFeed with a taken URL succeeded")
+	}
+	_, err = e.q.CreateFeed(e.ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Name: "orphan", Url: "https://orphan.example/", UserID: uuid.New(),
+	})
+	if err == nil {
+		t.Error("CreateFeed for a missing user succeeded")
+	}
+
+	// Names aren't unique
+	e.feed(alice, "news2")
+	if _, err := e.q.CreateFeed(e.ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Name: "news", Url: "https://other-news.example/", UserID: alice.ID,
+	}); err != nil {
+		t.Fatalf("CreateFeed with a taken name: %v", err)
+	}
+	if byName, err := e.q.GetFeedsByName(e.ctx, "news"); err != nil || len(byName) != 2 {
+		t.Errorf("GetFeedsByName = %d feeds, %v; want 2", len(byName), err)
+	}
+
+	all, err := e.q.GetAllFeeds(e.ctx)
+	if err != nil {
+		t.Fatalf("GetAllFeeds: %v", err)
+	}
+	var names []string
+	for _, f := range all {
+		names = append(names, f.Name)
+	}
+	if want := []string{"blog", "news", "news", "news2"}; !equal(names, want) {
+		t.Errorf("GetAllFeeds = %v, want %v", names, want)
+	}
+
+	err = e.q.SetFeedFetchFullArticle(e.ctx, database.SetFeedFetchFullArticleParams{FetchFullArticle: true, UpdatedAt: e.base, ID: news.ID})
+	if err != nil {
+		t.Fatalf("SetFeedFetchFullArticle: %v", err)
+	}
+	err = e.q.SetFeedRetention(e.ctx, database.SetFeedRetentionParams{
+		RetentionDays: sql.NullInt32{Int32: 30, Valid: true}, UpdatedAt: e.base, ID: news.ID,
+	})
+	if err != nil {
+		t.Fatalf("SetFeedRetention: %v", err)
+	}
+	got, _ = e.q.GetFeed(e.ctx, news.ID)
+	if !got.FetchFullArticle || got.RetentionDays != (sql.NullInt32{Int32: 30, Valid: true}) || got.RetentionKeep.Valid {
+		t.Errorf("after updates GetFeed = %+v", got)
+	}
+}
+
+func testFeedScheduling(t *testing.T, e *env) {
+	alice := e.user("alice")
+	old := e.feed(alice, "old")
+	recent := e.feed(alice, "recent")
+	never := e.feed(alice, "never")
+
+	mark := func(f database.Feed, at time.Duration) {
+		t.Helper()
+		err := e.q.MarkFeedFetched(e.ctx, database.MarkFeedFetchedParams{LastFetchedAt: sql.NullTime{Time: e.at(at), Valid: true}, ID: f.ID})
+		if err != nil {
+			t.Fatalf("MarkFeedFetched: %v", err)
+		}
+	}
+	mark(old, -3*time.Hour)
+	mark(recent, -10*time.Minute)
+
+	next, err := e.q.GetNextFeedToFetch(e.ctx)
+	if err != nil || next.ID != never.ID {
+		t.Errorf("GetNextFeedToFetch = %s, %v; want the feed never fetched", next.Name, err)
+	}
+
+	cutoff := sql.NullTime{Time: e.at(-time.Hour), Valid: true}
+	due, err := e.q.GetFeedsDueForFetch(e.ctx, cutoff)
+	if err != nil {
+		t.Fatalf("GetFeedsDueForFetch: %v", err)
+	}
+	var names []string
+	for _, f := range due {
+		names = append(names, f.Name)
+	}
+	if want := []string{"never", "old"}; !equal(names, want) {
+		t.Errorf("GetFeedsDueForFetch = %v, want %v", names, want)
+	}
+	if n, err := e.q.CountFeedsDueForFetch(e.ctx, cutoff); err != nil || n != 2 {
+		t.Errorf("CountFeedsDueForFetch = %d, %v; want 2", n, err)
+	}
+
+	mark(never, 0)
+	if next, _ := e.q.GetNextFeedToFetch(e.ctx); next.ID != old.ID {
+		t.Errorf("GetNextFeedToFetch = %s, want the least recently fetched", next.Name)
+	}
+	got, _ := e.q.GetFeed(e.ctx, never.ID)
+	if !got.LastFetchedAt.Valid || !got.LastFetchedAt.Time.Equal(e.base) || !got.UpdatedAt.Equal(e.base) {
+		t.Errorf("after MarkFeedFetched GetFeed = %+v", got)
+	}
+}
+
+func testFeedFollows(t *testing.T, e *env) {
+	alice := e.user("alice")
+	bob := e.user("bob")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+
+	row, err := e.q.CreateFeedFollow(e.ctx, database.CreateFeedFollowParams{
+		ID: uuid.New(), CreatedAt: e.at(time.Minute), UpdatedAt: e.at(time.Minute), UserID: bob.ID, FeedID: news.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeedFollow: %v", err)
+	}
+	if row.UserName != "bob" || row.FeedName != "news" {
+		t.Errorf("CreateFeedFollow = %+v", row)
+	}
+	e.follow(bob, blog)
+
+	_, err = e.q.CreateFeedFollow(e.ctx, database.CreateFeedFollowParams{
+		ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, UserID: bob.ID, FeedID: news.ID,
+	})
+	if err == nil {
+		t.Error("following a feed twice succeeded")
+	}
+
+	p := e.post(news, "one", ago(0))
+	e.post(news, "two", ago(time.Minute))
+	e.read(bob, p)
+
+	follows, err := e.q.GetFeedFollowsForUser(e.ctx, bob.ID)
+	if err != nil {
+		t.Fatalf("GetFeedFollowsForUser: %v", err)
+	}
+	if len(follows) != 2 || follows[0].FeedName != "news" || follows[1].FeedName != "blog" {
+		t.Fatalf("GetFeedFollowsForUser = %+v, want news then blog", follows)
+	}
+	if follows[0].UnreadCount != 1 || follows[1].UnreadCount != 0 || follows[0].FeedUrl != news.Url {
+		t.Errorf("GetFeedFollowsForUser = %+v, want 1 unread in news", follows)
+	}
+
+	if _, err := e.q.GetFeedFollowByUserAndFeed(e.ctx, database.GetFeedFollowByUserAndFeedParams{UserID: alice.ID, FeedID: news.ID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedFollowByUserAndFeed for a feed not followed: %v, want sql.ErrNoRows", err)
+	}
+
+	err = e.q.DeleteFeedFollowByUserAndFeedURL(e.ctx, database.DeleteFeedFollowByUserAndFeedURLParams{UserID: bob.ID, Url: news.Url})
+	if err != nil {
+		t.Fatalf("DeleteFeedFollowByUserAndFeedURL: %v", err)
+	}
+	follows, _ = e.q.GetFeedFollowsForUser(e.ctx, bob.ID)
+	if len(follows) != 1 || follows[0].FeedName != "blog" {
+		t.Errorf("after unfollowing GetFeedFollowsForUser = %+v", follows)
+	}
+}
+
+func testDeleteCascades(t *testing.T, e *env) {
+	alice := e.user("alice")
+	bob := e.user("bob")
+	news := e.feed(alice, "news")
+	e.follow(bob, news)
+	p := e.post(news, "one", ago(0))
+	e.read(bob, p)
+
+	if err := e.q.DeleteFeed(e.ctx, news.ID); err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+	if n, _ := e.q.GetPostsCount(e.ctx); n != 0 {
+		t.Errorf("%d posts left after deleting their feed", n)
+	}
+	if follows, _ := e.q.GetFeedFollowsForUser(e.ctx, bob.ID); len(follows) != 0 {
+		t.Errorf("%d follows left after deleting their feed", len(follows))
+	}
+
+	blog := e.feed(alice, "blog")
+	e.follow(bob, blog)
+	e.post(blog, "two", ago(0))
+	if err := e.q.DeleteAllUsers(e.ctx); err != nil {
+		t.Fatalf("DeleteAllUsers: %v", err)
+	}
+	if feeds, _ := e.q.GetAllFeeds(e.ctx); len(feeds) != 0 {
+		t.Errorf("%d feeds left after deleting every user", len(feeds))
+	}
+	if n, _ := e.q.GetPostsCount(e.ctx); n != 0 {
+		t.Errorf("%d posts left after deleting every user", n)
+	}
+}
+
+func testPosts(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	p := e.post(news, "one", ago(-time.Hour))
+
+	got, err := e.q.GetPostByURL(e.ctx, p.Url)
+	if err != nil || got.ID != p.ID || !got.PublishedAt.Time.Equal(e.at(-time.Hour)) || got.Content.Valid {
+		t.Errorf("GetPostByURL = %+v, %v", got, err)
+	}
+
+	_, err = e.q.CreatePost(e.ctx, database.CreatePostParams{
+		ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Title: "dup", Url: p.Url, FeedID: news.ID,
+	})
+	if err == nil {
+		t.Error("CreatePost with a taken URL succeeded")
+	}
+	_, err = e.q.CreatePost(e.ctx, database.CreatePostParams{
+		ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Title: "orphan", Url: "https://orphan.example/1", FeedID: uuid.New(),
+	})
+	if err == nil {
+		t.Error("CreatePost for a missing feed succeeded")
+	}
+
+	err = e.q.UpdatePostContent(e.ctx, database.UpdatePostContentParams{
+		Content: sql.NullString{String: "<p>full</p>", Valid: true}, UpdatedAt: e.at(time.Hour), ID: p.ID,
+	})
+	if err != nil {
+		t.Fatalf("UpdatePostContent: %v", err)
+	}
+	got, _ = e.q.GetPostByURL(e.ctx, p.Url)
+	if got.Content.String != "<p>full</p>" || !got.UpdatedAt.Equal(e.at(time.Hour)) {
+		t.Errorf("after UpdatePostContent GetPostByURL = %+v", got)
+	}
+
+	// Categories and authors are shared between posts, matched by name
+	p2 := e.post(news, "two", nil)
+	e.tag(p, "go")
+	e.tag(p2, "go")
+	e.tag(p, "databases")
+	e.author(p, "Ann", "ann@example.com")
+	cats, err := e.q.GetCategoriesForPost(e.ctx, p.ID)
+	if err != nil || len(cats) != 2 || cats[0].Name != "databases" || cats[1].Name != "go" {
+		t.Errorf("GetCategoriesForPost = %+v, %v", cats, err)
+	}
+	cats2, _ := e.q.GetCategoriesForPost(e.ctx, p2.ID)
+	if len(cats2) != 1 || cats2[0].ID != cats[1].ID {
+		t.Errorf("the go category wasn't shared: %+v and %+v", cats, cats2)
+	}
+	if authors, err := e.q.GetAuthorsForPost(e.ctx, p.ID); err != nil || len(authors) != 1 || authors[0].Email != "ann@example.com" {
+		t.Errorf("GetAuthorsForPost = %+v, %v", authors, err)
+	}
+
+	if n, err := e.q.CountNewerPosts(e.ctx, database.CountNewerPostsParams{
+		FeedID: news.ID, PublishedAt: sql.NullTime{Time: e.at(-2 * time.Hour), Valid: true},
+	}); err != nil || n != 2 {
+		t.Errorf("CountNewerPosts = %d, %v; want 2 counting the undated post", n, err)
+	}
+	if n, _ := e.q.GetPostsCount(e.ctx); n != 2 {
+		t.Errorf("GetPostsCount = %d, want 2", n)
+	}
+}
+
+func testTransactions(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	taken := e.post(news, "taken", nil)
+
+	// create saves a post and tags it, as savePost does
+	create := func(q database.Querier, url string) error {
+		p, err := q.CreatePost(e.ctx, database.CreatePostParams{
+			ID: uuid.New(), CreatedAt: e.base, UpdatedAt: e.base, Title: "new", Url: url, FeedID: news.ID,
+		})
+		if err != nil {
+			return err
+		}
+		c, err := q.UpsertCategory(e.ctx, database.UpsertCategoryParams{ID: uuid.New(), CreatedAt: e.base, Name: "go"})
+		if err != nil {
+			return err
+		}
+		return q.AddPostCategory(e.ctx, database.AddPostCategoryParams{PostID: p.ID, CategoryID: c.ID})
+	}
+
+	// A failing query rolls back what came before it
+	err := e.q.InTx(e.ctx, func(q database.Querier) error {
+		if err := create(q, "https://news.example/first"); err != nil {
+			return err
+		}
+		return create(q, taken.Url)
+	})
+	if err == nil {
+		t.Error("InTx creating a post with a taken URL succeeded")
+	}
+	if _, err := e.q.GetPostByURL(e.ctx, "https://news.example/first"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("after a rollback GetPostByURL = %v, want sql.ErrNoRows", err)
+	}
+	id := uuid.New()
+	if c, err := e.q.UpsertCategory(e.ctx, database.UpsertCategoryParams{ID: id, CreatedAt: e.base, Name: "go"}); err != nil || c.ID != id {
+		t.Errorf("the category created before the rollback was kept: %+v, %v", c, err)
+	}
+
+	// fn's own error is returned as is
+	errStop := errors.New("stop")
+	err = e.q.InTx(e.ctx, func(q database.Querier) error {
+		if err := create(q, "https://news.example/second"); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("InTx = %v, want the error fn returned", err)
+	}
+	if _, err := e.q.GetPostByURL(e.ctx, "https://news.example/second"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("after a rollback GetPostByURL = %v, want sql.ErrNoRows", err)
+	}
+
+	err = e.q.InTx(e.ctx, func(q database.Querier) error {
+		return create(q, "https://news.example/third")
+	})
+	if err != nil {
+		t.Fatalf("InTx: %v", err)
+	}
+	p, err := e.q.GetPostByURL(e.ctx, "https://news.example/third")
+	if err != nil {
+		t.Fatalf("after a commit GetPostByURL: %v", err)
+	}
+	if cats, err := e.q.GetCategoriesForPost(e.ctx, p.ID); err != nil || len(cats) != 1 || cats[0].ID != id {
+		t.Errorf("after a commit GetCategoriesForPost = %+v, %v", cats, err)
+	}
+}
+
+func testPostsByIDPrefix(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	other := e.feed(alice, "other")
+	e.follow(alice, news)
+	a := e.postWithID(uuid.MustParse("abcd0000-0000-4000-8000-000000000001"), news, "a", nil)
+	b := e.postWithID(uuid.MustParse("abcd0000-0000-4000-8000-000000000002"), news, "b", nil)
+	c := e.postWithID(uuid.MustParse("abce0000-0000-4000-8000-000000000003"), news, "c", nil)
+	e.postWithID(uuid.MustParse("abcd0000-0000-4000-8000-000000000000"), other, "unfollowed", nil)
+
+	// lookup returns the titles of alice's posts with an ID starting with
+	// hex, which is whole bytes
+	lookup := func(hex string) []string {
+		t.Helper()
+		lower, upper := idRange(hex)
+		posts, err := e.q.GetPostsByIDPrefix(e.ctx, database.GetPostsByIDPrefixParams{UserID: alice.ID, Lower: lower, Upper: upper})
+		if err != nil {
+			t.Fatalf("GetPostsByIDPrefix(%s): %v", hex, err)
+		}
+		titles := []string{}
+		for _, p := range posts {
+			titles = append(titles, p.Title)
+		}
+		return titles
+	}
+
+	tests := []struct {
+		hex  string
+		want []string
+	}{
+		{strings.ReplaceAll(a.ID.String(), "-", ""), []string{"a"}},
+		{strings.ReplaceAll(c.ID.String(), "-", ""), []string{"c"}},
+		{"abcd", []string{"a", "b"}},
+		{"abce", []string{"c"}},
+		{"ab", []string{"a", "b"}},
+		{"ff", []string{}},
+	}
+	for _, tt := range tests {
+		if got := lookup(tt.hex); !equal(got, tt.want) {
+			t.Errorf("GetPostsByIDPrefix(%s) = %v, want %v", tt.hex, got, tt.want)
+		}
+	}
+
+	if posts, err := e.q.GetPostsByIDPrefix(e.ctx, database.GetPostsByIDPrefixParams{UserID: alice.ID, Lower: b.ID, Upper: b.ID}); err != nil || len(posts) != 1 || posts[0].ID != b.ID {
+		t.Errorf("GetPostsByIDPrefix of a single ID = %+v, %v", posts, err)
+	}
+}
+
+func testBrowseFilters(t *testing.T, e *env) {
+	alice := e.user("alice")
+	bob := e.user("bob")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+	other := e.feed(alice, "other")
+	e.follow(bob, news)
+	e.follow(bob, blog)
+
+	n1 := e.post(news, "n1", ago(-3*24*time.Hour))
+	n2 := e.post(news, "n2", ago(-24*time.Hour))
+	b1 := e.post(blog, "b1", ago(-2*24*time.Hour))
+	e.post(blog, "b2", nil)
+	e.post(other, "o1", ago(0))
+
+	e.tag(n1, "go")
+	e.tag(b1, "go")
+	e.tag(n2, "rust")
+	e.author(n2, "Ann Author", "ann@example.com")
+	e.read(bob, n2)
+	e.save(bob, b1, 0, "")
+
+	tests := []struct {
+		name   string
+		params database.GetPostsForUserParams
+		want   []string
+	}{
+		{"everything followed", database.GetPostsForUserParams{}, []string{"b2", "n2", "b1", "n1"}},
+		{"unread only", database.GetPostsForUserParams{UnreadOnly: true}, []string{"b2", "b1", "n1"}},
+		{"tag", database.GetPostsForUserParams{Tag: sql.NullString{String: "go", Valid: true}}, []string{"b1", "n1"}},
+		{"tag is case-insensitive", database.GetPostsForUserParams{Tag: sql.NullString{String: "Rust", Valid: true}}, []string{"n2"}},
+		{"author by name", database.GetPostsForUserParams{Author: sql.NullString{String: "ann author", Valid: true}}, []string{"n2"}},
+		{"author by email", database.GetPostsForUserParams{Author: sql.NullString{String: "ANN@example.com", Valid: true}}, []string{"n2"}},
+		{"feed", database.GetPostsForUserParams{FeedID: uuid.NullUUID{UUID: news.ID, Valid: true}}, []string{"n2", "n1"}},
+		{"since leaves out undated posts", database.GetPostsForUserParams{Since: sql.NullTime{Time: e.at(-2 * 24 * time.Hour), Valid: true}}, []string{"n2", "b1"}},
+		{"until is exclusive", database.GetPostsForUserParams{Until: sql.NullTime{Time: e.at(-2 * 24 * time.Hour), Valid: true}}, []string{"n1"}},
+		{"saved only", database.GetPostsForUserParams{SavedOnly: true}, []string{"b1"}},
+		{"filters combine", database.GetPostsForUserParams{Tag: sql.NullString{String: "go", Valid: true}, FeedID: uuid.NullUUID{UUID: blog.ID, Valid: true}}, []string{"b1"}},
+		{"limit", database.GetPostsForUserParams{Limit: 2}, []string{"b2", "n2"}},
+		{"offset", database.GetPostsForUserParams{Offset: 3}, []string{"n1"}},
+	}
+	for _, tt := range tests {
+		tt.params.UserID = bob.ID
+		if got := e.browse(tt.params); !equal(got, tt.want) {
+			t.Errorf("%s: GetPostsForUser = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID}); len(got) != 0 {
+		t.Errorf("alice follows nothing but sees %v", got)
+	}
+
+	rows, err := e.q.GetPostsForUser(e.ctx, database.GetPostsForUserParams{UserID: bob.ID, FeedID: uuid.NullUUID{UUID: news.ID, Valid: true}, Sort: "published", Limit: 10})
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("GetPostsForUser = %d rows, %v", len(rows), err)
+	}
+	if r := rows[0]; r.FeedName != "news" || r.Categories != "rust" || r.Authors != "Ann Author" || !r.Read {
+		t.Errorf("GetPostsForUser row = %+v", r)
+	}
+	if r := rows[1]; r.Categories != "go" || r.Authors != "" || r.Read {
+		t.Errorf("GetPostsForUser row = %+v", r)
+	}
+}
+
+func testBrowseSorts(t *testing.T, e *env) {
+	alice := e.user("alice")
+	zeta := e.feed(alice, "zeta")
+	alpha := e.feed(alice, "alpha")
+	e.follow(alice, zeta)
+	e.follow(alice, alpha)
+
+	// Stored in this order, a second apart
+	e.post(zeta, "z-old", ago(-2*time.Hour))
+	e.post(alpha, "a-new", ago(-time.Hour))
+	e.post(zeta, "z-new", ago(0))
+	e.post(alpha, "a-undated", nil)
+
+	for _, tt := range []struct {
+		sort string
+		want []string
+	}{
+		{"published", []string{"a-undated", "z-new", "a-new", "z-old"}},
+		{"fetched", []string{"a-undated", "z-new", "a-new", "z-old"}},
+		{"feed", []string{"a-undated", "a-new", "z-new", "z-old"}},
+	} {
+		if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID, Sort: tt.sort}); !equal(got, tt.want) {
+			t.Errorf("sort %s: GetPostsForUser = %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+
+	// Fetched order differs from published order once a post arrives late
+	e.post(zeta, "z-late", ago(-3*time.Hour))
+	got := e.browse(database.GetPostsForUserParams{UserID: alice.ID, Sort: "fetched"})
+	if len(got) != 5 || got[0] != "z-late" {
+		t.Errorf("sort fetched: GetPostsForUser = %v, want the latest stored first", got)
+	}
+}
+
+// testBrowsePaging walks the published order a page at a time, both with the
+// (published_at, id) cursor and with offsets, over posts that share dates or
+// have none. Every post must come up exactly once and in the same order as
+// a single unpaged query.
+func testBrowsePaging(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+	e.follow(alice, news)
+	e.follow(alice, blog)
+
+	type post struct {
+		id        uuid.UUID
+		title     string
+		published *time.Duration
+	}
+	var posts []post
+	add := func(f database.Feed, title string, published *time.Duration) {
+		p := e.post(f, title, published)
+		posts = append(posts, post{p.ID, title, published})
+	}
+	for i := 0; i < 3; i++ {
+		add(news, fmt.Sprintf("undated-%d", i), nil)
+	}
+	for i := 0; i < 4; i++ {
+		add(news, fmt.Sprintf("same-%d", i), ago(-time.Hour))
+		add(blog, fmt.Sprintf("same-blog-%d", i), ago(-time.Hour))
+	}
+	add(news, "newest", ago(0))
+	add(blog, "oldest", ago(-48*time.Hour))
+	add(blog, "between", ago(-2*time.Hour))
+	add(news, "also-undated", nil)
+
+	// Undated posts first, then newest first, then by ID, descending
+	sort.Slice(posts, func(i, j int) bool {
+		a, b := posts[i], posts[j]
+		if (a.published == nil) != (b.published == nil) {
+			return a.published == nil
+		}
+		if a.published != nil && *a.published != *b.published {
+			return *a.published > *b.published
+		}
+		return bytes.Compare(a.id[:], b.id[:]) > 0
+	})
+	var want []string
+	for _, p := range posts {
+		want = append(want, p.title)
+	}
+
+	if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID}); !equal(got, want) {
+		t.Fatalf("GetPostsForUser =\n%v\nwant\n%v", got, want)
+	}
+
+	for _, size := range []int32{1, 2, 3, 5} {
+		// Keyset paging from the last post of each page
+		var got []string
+		params := database.GetPostsForUserParams{UserID: alice.ID, Sort: "published", Limit: size}
+		for page := 0; page < len(want)+1; page++ {
+			rows, err := e.q.GetPostsForUser(e.ctx, params)
+			if err != nil {
+				t.Fatalf("GetPostsForUser: %v", err)
+			}
+			for _, r := range rows {
+				got = append(got, r.Title)
+			}
+			if len(rows) < int(size) {
+				break
+			}
+			last := rows[len(rows)-1]
+			params.BeforeID = uuid.NullUUID{UUID: last.ID, Valid: true}
+			params.BeforePublishedAt = last.PublishedAt
+		}
+		if !equal(got, want) {
+			t.Errorf("pages of %d by cursor =\n%v\nwant\n%v", size, got, want)
+		}
+
+		// Offset paging
+		got = nil
+		for offset := int32(0); offset < int32(len(want)); offset += size {
+			got = append(got, e.browse(database.GetPostsForUserParams{UserID: alice.ID, Limit: size, Offset: offset})...)
+		}
+		if !equal(got, want) {
+			t.Errorf("pages of %d by offset =\n%v\nwant\n%v", size, got, want)
+		}
+	}
+
+	// The cursor combines with filters
+	var got []string
+	params := database.GetPostsForUserParams{UserID: alice.ID, FeedID: uuid.NullUUID{UUID: blog.ID, Valid: true}, Sort: "published", Limit: 2}
+	for {
+		rows, err := e.q.GetPostsForUser(e.ctx, params)
+		if err != nil {
+			t.Fatalf("GetPostsForUser: %v", err)
+		}
+		for _, r := range rows {
+			got = append(got, r.Title)
+		}
+		if len(rows) < 2 {
+			break
+		}
+		last := rows[len(rows)-1]
+		params.BeforeID = uuid.NullUUID{UUID: last.ID, Valid: true}
+		params.BeforePublishedAt = last.PublishedAt
+	}
+	var wantBlog []string
+	for _, title := range want {
+		if len(title) >= 9 && title[:9] == "same-blog" || title == "oldest" || title == "between" {
+			wantBlog = append(wantBlog, title)
+		}
+	}
+	if !equal(got, wantBlog) {
+		t.Errorf("blog pages by cursor =\n%v\nwant\n%v", got, wantBlog)
+	}
+}
+
+func testReads(t *testing.T, e *env) {
+	alice := e.user("alice")
+	bob := e.user("bob")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+	e.follow(alice, news)
+	e.follow(alice, blog)
+	e.follow(bob, news)
+
+	n1 := e.post(news, "n1", ago(-2*time.Hour))
+	e.post(news, "n2", ago(0))
+	e.post(news, "n3", nil)
+	e.post(blog, "b1", ago(-3*time.Hour))
+
+	// Reading twice is fine, and only marks it for that user
+	e.read(alice, n1)
+	e.read(alice, n1)
+	unread := database.GetPostsForUserParams{UserID: alice.ID, UnreadOnly: true}
+	if got := e.browse(unread); !equal(got, []string{"n3", "n2", "b1"}) {
+		t.Errorf("alice's unread = %v", got)
+	}
+	if got := e.browse(database.GetPostsForUserParams{UserID: bob.ID, UnreadOnly: true}); len(got) != 3 {
+		t.Errorf("bob's unread = %v, want all of news", got)
+	}
+
+	if err := e.q.MarkPostUnread(e.ctx, database.MarkPostUnreadParams{UserID: alice.ID, PostID: n1.ID}); err != nil {
+		t.Fatalf("MarkPostUnread: %v", err)
+	}
+	if got := e.browse(unread); len(got) != 4 {
+		t.Errorf("after MarkPostUnread alice's unread = %v", got)
+	}
+
+	// Undated posts aren't before anything
+	n, err := e.q.MarkPostsRead(e.ctx, database.MarkPostsReadParams{
+		ReadAt: e.base, UserID: alice.ID, Before: sql.NullTime{Time: e.at(-time.Hour), Valid: true},
+	})
+	if err != nil || n != 2 {
+		t.Errorf("MarkPostsRead before an hour ago = %d, %v; want 2", n, err)
+	}
+	if got := e.browse(unread); !equal(got, []string{"n3", "n2"}) {
+		t.Errorf("alice's unread = %v", got)
+	}
+
+	n, err = e.q.MarkPostsRead(e.ctx, database.MarkPostsReadParams{
+		ReadAt: e.base, UserID: alice.ID, FeedID: uuid.NullUUID{UUID: news.ID, Valid: true},
+	})
+	if err != nil || n != 2 {
+		t.Errorf("MarkPostsRead of news = %d, %v; want the 2 still unread", n, err)
+	}
+	if got := e.browse(unread); len(got) != 0 {
+		t.Errorf("alice's unread = %v, want none", got)
+	}
+	if got := e.browse(database.GetPostsForUserParams{UserID: bob.ID, UnreadOnly: true}); len(got) != 3 {
+		t.Errorf("bob's unread = %v, want all of news", got)
+	}
+}
+
+func testSavedPosts(t *testing.T, e *env) {
+	alice := e.user("alice")
+	bob := e.user("bob")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "Blog")
+	p1 := e.post(news, "Postgres internals", ago(-time.Hour))
+	p2 := e.post(blog, "Go generics", nil)
+	p3 := e.post(blog, "Rust lifetimes", ago(0))
+
+	s1 := e.save(alice, p1, time.Hour, "")
+	if s1.PostID != p1.ID || s1.Title != p1.Title || s1.Url != p1.Url || s1.FeedName != "news" ||
+		!s1.PublishedAt.Time.Equal(p1.PublishedAt.Time) || s1.Description != p1.Description || !s1.CreatedAt.Equal(e.at(time.Hour)) {
+		t.Errorf("CreateSavedPost = %+v, want a copy of %+v", s1, p1)
+	}
+	s2 := e.save(alice, p2, 2*time.Hour, "read later")
+	e.save(alice, p3, 3*time.Hour, "")
+	e.save(bob, p1, time.Hour, "")
+
+	_, err := e.q.CreateSavedPost(e.ctx, database.CreateSavedPostParams{ID: uuid.New(), UserID: alice.ID, CreatedAt: e.base, PostID: p1.ID})
+	if err == nil {
+		t.Error("saving a post twice succeeded")
+	}
+
+	if got, err := e.q.GetSavedPost(e.ctx, database.GetSavedPostParams{UserID: alice.ID, PostID: p2.ID}); err != nil || got.ID != s2.ID {
+		t.Errorf("GetSavedPost = %+v, %v", got, err)
+	}
+	if got, err := e.q.GetSavedPostByURL(e.ctx, database.GetSavedPostByURLParams{UserID: alice.ID, Url: p2.Url}); err != nil || got.ID != s2.ID {
+		t.Errorf("GetSavedPostByURL = %+v, %v", got, err)
+	}
+	if _, err := e.q.GetSavedPost(e.ctx, database.GetSavedPostParams{UserID: bob.ID, PostID: p2.ID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetSavedPost of another user's save: %v, want sql.ErrNoRows", err)
+	}
+	lower, upper := idRange(p1.ID.String()[:8])
+	byPrefix, err := e.q.GetSavedPostsByIDPrefix(e.ctx, database.GetSavedPostsByIDPrefixParams{UserID: bob.ID, Lower: lower, Upper: upper})
+	if err != nil || len(byPrefix) != 1 || byPrefix[0].PostID != p1.ID {
+		t.Errorf("GetSavedPostsByIDPrefix of bob's save = %+v, %v", byPrefix, err)
+	}
+	lower, upper = idRange("")
+	byPrefix, err = e.q.GetSavedPostsByIDPrefix(e.ctx, database.GetSavedPostsByIDPrefixParams{UserID: alice.ID, Lower: lower, Upper: upper})
+	if err != nil || len(byPrefix) != 2 || bytes.Compare(byPrefix[0].PostID[:], byPrefix[1].PostID[:]) >= 0 {
+		t.Errorf("GetSavedPostsByIDPrefix of every ID = %+v, %v; want the first 2 of alice's 3 saves", byPrefix, err)
+	}
+
+	for _, tag := range []string{"db", "later", "db"} {
+		if err := e.q.AddSavedPostTag(e.ctx, database.AddSavedPostTagParams{SavedPostID: s1.ID, Tag: tag}); err != nil {
+			t.Fatalf("AddSavedPostTag(%q): %v", tag, err)
+		}
+	}
+	if err := e.q.AddSavedPostTag(e.ctx, database.AddSavedPostTagParams{SavedPostID: s2.ID, Tag: "later"}); err != nil {
+		t.Fatalf("AddSavedPostTag: %v", err)
+	}
+	if err := e.q.SetSavedPostNote(e.ctx, database.SetSavedPostNoteParams{Note: "Great read", UpdatedAt: e.at(4 * time.Hour), ID: s1.ID}); err != nil {
+		t.Fatalf("SetSavedPostNote: %v", err)
+	}
+
+	saved := func(params database.GetSavedPostsParams) []string {
+		t.Helper()
+		params.UserID = alice.ID
+		if params.Limit == 0 {
+			params.Limit = 100
+		}
+		rows, err := e.q.GetSavedPosts(e.ctx, params)
+		if err != nil {
+			t.Fatalf("GetSavedPosts: %v", err)
+		}
+		titles := []string{}
+		for _, r := range rows {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	tests := []struct {
+		name   string
+		params database.GetSavedPostsParams
+		want   []string
+	}{
+		{"latest first", database.GetSavedPostsParams{}, []string{"Rust lifetimes", "Go generics", "Postgres internals"}},
+		{"tag", database.GetSavedPostsParams{Tag: sql.NullString{String: "later", Valid: true}}, []string{"Go generics", "Postgres internals"}},
+		{"feed name ignores case", database.GetSavedPostsParams{FeedName: sql.NullString{String: "blog", Valid: true}}, []string{"Rust lifetimes", "Go generics"}},
+		{"text in title", database.GetSavedPostsParams{Text: sql.NullString{String: "GENERICS", Valid: true}}, []string{"Go generics"}},
+		{"text in note", database.GetSavedPostsParams{Text: sql.NullString{String: "great", Valid: true}}, []string{"Postgres internals"}},
+		{"since", database.GetSavedPostsParams{Since: sql.NullTime{Time: e.at(2 * time.Hour), Valid: true}}, []string{"Rust lifetimes", "Go generics"}},
+		{"until is exclusive", database.GetSavedPostsParams{Until: sql.NullTime{Time: e.at(2 * time.Hour), Valid: true}}, []string{"Postgres internals"}},
+		{"limit", database.GetSavedPostsParams{Limit: 1}, []string{"Rust lifetimes"}},
+	}
+	for _, tt := range tests {
+		if got := saved(tt.params); !equal(got, tt.want) {
+			t.Errorf("%s: GetSavedPosts = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	rows, _ := e.q.GetSavedPosts(e.ctx, database.GetSavedPostsParams{UserID: alice.ID, Tag: sql.NullString{String: "db", Valid: true}, Limit: 10})
+	if len(rows) != 1 || rows[0].Tags != "db, later" || rows[0].Note != "Great read" || !rows[0].UpdatedAt.Equal(e.at(4*time.Hour)) {
+		t.Errorf("GetSavedPosts row = %+v", rows)
+	}
+
+	if err := e.q.RemoveSavedPostTag(e.ctx, database.RemoveSavedPostTagParams{SavedPostID: s1.ID, Tag: "db"}); err != nil {
+		t.Fatalf("RemoveSavedPostTag: %v", err)
+	}
+	if got := saved(database.GetSavedPostsParams{Tag: sql.NullString{String: "db", Valid: true}}); len(got) != 0 {
+		t.Errorf("after RemoveSavedPostTag the tag still matches %v", got)
+	}
+
+	if err := e.q.DeleteSavedPost(e.ctx, s2.ID); err != nil {
+		t.Fatalf("DeleteSavedPost: %v", err)
+	}
+	if got := saved(database.GetSavedPostsParams{}); !equal(got, []string{"Rust lifetimes", "Postgres internals"}) {
+		t.Errorf("after DeleteSavedPost GetSavedPosts = %v", got)
+	}
+}
+
+func testSavedPostsOutliveTheirFeed(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	e.follow(alice, news)
+	kept := e.post(news, "kept", ago(-time.Hour))
+	e.post(news, "dropped", ago(0))
+	e.save(alice, kept, 0, "")
+
+	if err := e.q.DeletePostsByFeedID(e.ctx, news.ID); err != nil {
+		t.Fatalf("DeletePostsByFeedID: %v", err)
+	}
+	if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID}); !equal(got, []string{"kept"}) {
+		t.Errorf("after DeletePostsByFeedID GetPostsForUser = %v, want the saved post only", got)
+	}
+
+	if err := e.q.DeleteFeed(e.ctx, news.ID); err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+	rows, err := e.q.GetSavedPosts(e.ctx, database.GetSavedPostsParams{UserID: alice.ID, Limit: 10})
+	if err != nil || len(rows) != 1 || rows[0].Title != "kept" || rows[0].FeedName != "news" {
+		t.Errorf("after DeleteFeed GetSavedPosts = %+v, %v", rows, err)
+	}
+	if got, err := e.q.GetSavedPostByURL(e.ctx, database.GetSavedPostByURLParams{UserID: alice.ID, Url: kept.Url}); err != nil || got.PostID != kept.ID {
+		t.Errorf("after DeleteFeed GetSavedPostByURL = %+v, %v", got, err)
+	}
+
+	if err := e.q.DeleteAllUsers(e.ctx); err != nil {
+		t.Fatalf("DeleteAllUsers: %v", err)
+	}
+	if _, err := e.q.GetSavedPostByURL(e.ctx, database.GetSavedPostByURLParams{UserID: alice.ID, Url: kept.Url}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("after DeleteAllUsers GetSavedPostByURL: %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testRetention(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+	e.follow(alice, news)
+
+	e.post(news, "undated", nil)
+	e.post(news, "day-0", ago(0))
+	e.post(news, "day-1", ago(-24*time.Hour))
+	saved := e.post(news, "day-2", ago(-2*24*time.Hour))
+	e.post(news, "day-3", ago(-3*24*time.Hour))
+	e.post(blog, "blog-day-5", ago(-5*24*time.Hour))
+	e.save(alice, saved, 0, "")
+
+	expire := func(cutoff *time.Duration, keep int32) (int64, int64) {
+		t.Helper()
+		var c sql.NullTime
+		if cutoff != nil {
+			c = sql.NullTime{Time: e.at(*cutoff), Valid: true}
+		}
+		k := sql.NullInt32{Int32: keep, Valid: keep > 0}
+		count, err := e.q.CountExpiredPosts(e.ctx, database.CountExpiredPostsParams{FeedID: news.ID, Cutoff: c, Keep: k})
+		if err != nil {
+			t.Fatalf("CountExpiredPosts: %v", err)
+		}
+		deleted, err := e.q.DeleteExpiredPosts(e.ctx, database.DeleteExpiredPostsParams{FeedID: news.ID, Cutoff: c, Keep: k})
+		if err != nil {
+			t.Fatalf("DeleteExpiredPosts: %v", err)
+		}
+		return count, deleted
+	}
+
+	// Neither limit set expires nothing
+	if count, deleted := expire(nil, 0); count != 0 || deleted != 0 {
+		t.Errorf("no limits expired %d (counted %d)", deleted, count)
+	}
+
+	// Keeping 2 keeps the undated post and day-0; day-2 is saved, and
+	// still takes its place in the ranking
+	if count, deleted := expire(nil, 2); count != 2 || deleted != 2 {
+		t.Errorf("keep 2 expired %d (counted %d), want 2", deleted, count)
+	}
+	if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID}); !equal(got, []string{"undated", "day-0", "day-2"}) {
+		t.Errorf("after keep 2 GetPostsForUser = %v", got)
+	}
+
+	// A cutoff leaves undated and saved posts alone
+	e.post(news, "day-4", ago(-4*24*time.Hour))
+	if count, deleted := expire(ago(-12*time.Hour), 0); count != 1 || deleted != 1 {
+		t.Errorf("cutoff expired %d (counted %d), want 1", deleted, count)
+	}
+	if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID}); !equal(got, []string{"undated", "day-0", "day-2"}) {
+		t.Errorf("after the cutoff GetPostsForUser = %v", got)
+	}
+
+	if n, _ := e.q.GetPostsCount(e.ctx); n != 4 {
+		t.Errorf("GetPostsCount = %d, want 4 with the other feed's post untouched", n)
+	}
+}
+
+// testRetentionKeepsWhatBrowseShows keeps the newest posts of a feed whose
+// posts share dates, which must be the ones browse lists first
+func testRetentionKeepsWhatBrowseShows(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	e.follow(alice, news)
+
+	e.post(news, "undated", nil)
+	for i := 0; i < 6; i++ {
+		e.post(news, fmt.Sprintf("same-%d", i), ago(-time.Hour))
+	}
+	e.post(news, "older", ago(-2*time.Hour))
+
+	const keep = 4
+	before := e.browse(database.GetPostsForUserParams{UserID: alice.ID})
+	deleted, err := e.q.DeleteExpiredPosts(e.ctx, database.DeleteExpiredPostsParams{FeedID: news.ID, Keep: sql.NullInt32{Int32: keep, Valid: true}})
+	if err != nil {
+		t.Fatalf("DeleteExpiredPosts: %v", err)
+	}
+	if deleted != int64(len(before)-keep) {
+		t.Errorf("DeleteExpiredPosts = %d, want %d", deleted, len(before)-keep)
+	}
+	if got := e.browse(database.GetPostsForUserParams{UserID: alice.ID}); !equal(got, before[:keep]) {
+		t.Errorf("kept %v, want the first %d browse showed: %v", got, keep, before)
+	}
+}
+
+func testSearch(t *testing.T, e *env) {
+	alice := e.user("alice")
+	bob := e.user("bob")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+	other := e.feed(alice, "other")
+	e.follow(alice, news)
+	e.follow(alice, blog)
+	e.follow(bob, other)
+
+	e.post(news, "Postgres vacuum tuning", ago(-time.Hour))
+	e.post(news, "Postgres replication", ago(-2*time.Hour))
+	e.post(blog, "Kubernetes operators", ago(-3*time.Hour))
+	e.post(blog, "Vacuum cleaners reviewed", ago(-24*time.Hour))
+	e.post(other, "Postgres for bob", ago(0))
+
+	search := func(query string, params database.SearchPostsParams) []string {
+		t.Helper()
+		params.Query = query
+		params.UserID = alice.ID
+		if params.Limit == 0 {
+			params.Limit = 20
+		}
+		rows, err := e.q.SearchPosts(e.ctx, params)
+		if err != nil {
+			t.Fatalf("SearchPosts(%q): %v", query, err)
+		}
+		titles := []string{}
+		for _, r := range rows {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	tests := []struct {
+		query  string
+		params database.SearchPostsParams
+		want   []string
+	}{
+		{"postgres", database.SearchPostsParams{}, []string{"Postgres vacuum tuning", "Postgres replication"}},
+		{"POSTGRES vacuum", database.SearchPostsParams{}, []string{"Postgres vacuum tuning"}},
+		{"vacuum -postgres", database.SearchPostsParams{}, []string{"Vacuum cleaners reviewed"}},
+		{"kubernetes or replication", database.SearchPostsParams{}, []string{"Postgres replication", "Kubernetes operators"}},
+		{`"vacuum tuning"`, database.SearchPostsParams{}, []string{"Postgres vacuum tuning"}},
+		{`"tuning vacuum"`, database.SearchPostsParams{}, []string{}},
+		{"vacuum", database.SearchPostsParams{FeedID: uuid.NullUUID{UUID: blog.ID, Valid: true}}, []string{"Vacuum cleaners reviewed"}},
+		{"vacuum", database.SearchPostsParams{Since: sql.NullTime{Time: e.at(-2 * time.Hour), Valid: true}}, []string{"Postgres vacuum tuning"}},
+		{"vacuum", database.SearchPostsParams{Until: sql.NullTime{Time: e.at(-2 * time.Hour), Valid: true}}, []string{"Vacuum cleaners reviewed"}},
+		{`"vacuum tuning`, database.SearchPostsParams{}, []string{"Postgres vacuum tuning"}},
+		{"near or not", database.SearchPostsParams{}, []string{}},
+		{"missingword", database.SearchPostsParams{}, []string{}},
+	}
+	for _, tt := range tests {
+		// Ranking differs between stores, so only the matches are compared
+		if got := search(tt.query, tt.params); !sameSet(got, tt.want) {
+			t.Errorf("SearchPosts(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	if got := search("postgres", database.SearchPostsParams{Limit: 1}); len(got) != 1 {
+		t.Errorf("SearchPosts with limit 1 = %v", got)
+	}
+}
+
+func testFeedFetches(t *testing.T, e *env) {
+	alice := e.user("alice")
+	news := e.feed(alice, "news")
+	blog := e.feed(alice, "blog")
+
+	start := func(f database.Feed, at time.Duration) database.FeedFetch {
+		t.Helper()
+		fetch, err := e.q.CreateFeedFetch(e.ctx, database.CreateFeedFetchParams{ID: uuid.New(), FeedID: f.ID, StartedAt: e.at(at)})
+		if err != nil {
+			t.Fatalf("CreateFeedFetch: %v", err)
+		}
+		if fetch.FinishedAt.Valid || fetch.HttpStatus.Valid || fetch.Error.Valid || fetch.ItemsSeen != 0 {
+			t.Errorf("CreateFeedFetch = %+v, want an unfinished fetch", fetch)
+		}
+		return fetch
+	}
+	first := start(news, 0)
+	start(blog, time.Minute)
+	third := start(news, 2*time.Minute)
+
+	err := e.q.FinishFeedFetch(e.ctx, database.FinishFeedFetchParams{
+		ID:            first.ID,
+		FinishedAt:    sql.NullTime{Time: e.at(time.Second), Valid: true},
+		HttpStatus:    sql.NullInt32{Int32: 200, Valid: true},
+		Bytes:         1234,
+		ItemsSeen:     10,
+		ItemsInserted: 3,
+	})
+	if err != nil {
+		t.Fatalf("FinishFeedFetch: %v", err)
+	}
+	err = e.q.FinishFeedFetch(e.ctx, database.FinishFeedFetchParams{
+		ID:         third.ID,
+		FinishedAt: sql.NullTime{Time: e.at(3 * time.Minute), Valid: true},
+		Error:      sql.NullString{String: "timeout", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("FinishFeedFetch: %v", err)
+	}
+
+	all, err := e.q.GetFeedFetches(e.ctx, database.GetFeedFetchesParams{Limit: 10})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("GetFeedFetches = %d, %v; want 3", len(all), err)
+	}
+	if all[0].ID != third.ID || all[0].Error.String != "timeout" || all[0].HttpStatus.Valid || all[0].FeedName != "news" || all[0].FeedUrl != news.Url {
+		t.Errorf("latest fetch = %+v", all[0])
+	}
+	if f := all[2]; f.ID != first.ID || f.HttpStatus.Int32 != 200 || f.Bytes != 1234 || f.ItemsSeen != 10 || f.ItemsInserted != 3 || f.Error.Valid {
+		t.Errorf("first fetch = %+v", f)
+	}
+
+	newsOnly, _ := e.q.GetFeedFetches(e.ctx, database.GetFeedFetchesParams{FeedID: uuid.NullUUID{UUID: news.ID, Valid: true}, Limit: 10})
+	if len(newsOnly) != 2 {
+		t.Errorf("GetFeedFetches for news = %d, want 2", len(newsOnly))
+	}
+	if limited, _ := e.q.GetFeedFetches(e.ctx, database.GetFeedFetchesParams{Limit: 1}); len(limited) != 1 || limited[0].ID != third.ID {
+		t.Errorf("GetFeedFetches with limit 1 = %+v", limited)
+	}
+}
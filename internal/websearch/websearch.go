@@ -0,0 +1,102 @@
+// Package websearch parses search queries written in the syntax of
+// Postgres's websearch_to_tsquery, so that the backends without it can
+// understand the same queries:
+//
+//	pgvector index          both words
+//	"vector search"         the phrase
+//	postgres or sqlite      either word
+//	postgres -mysql         postgres but not mysql
+package websearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Highlight markers surround the matched words in search snippets. The SQL
+// queries that build snippets use the same characters.
+const (
+	HighlightStart = "⟪"
+	HighlightStop  = "⟫"
+)
+
+// Term is a word, or a phrase when it has several words, that a document
+// must contain, or must not when Not is set
+type Term struct {
+	Words []string
+	Not   bool
+}
+
+// Query is a list of alternatives, any one of which matches a document
+// when all of its terms do. An empty Query matches nothing.
+type Query [][]Term
+
+// Parse parses a search query. Words are lowercased and split on anything
+// that isn't a letter or digit, so "pg-vector" is the phrase "pg vector".
+// An alternative made up only of excluded terms is dropped, as it would
+// match nearly everything.
+func Parse(s string) Query {
+	var query Query
+	var group []Term
+	flush := func() {
+		for _, t := range group {
+			if !t.Not {
+				query = append(query, group)
+				break
+			}
+		}
+		group = nil
+	}
+
+	for s != "" {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			break
+		}
+
+		not := false
+		if s[0] == '-' {
+			not = true
+			s = s[1:]
+		}
+
+		var token string
+		quoted := false
+		if strings.HasPrefix(s, `"`) {
+			quoted = true
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				token, s = s[1:], ""
+			} else {
+				token, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(s)
+			}
+			token, s = s[:end], s[end:]
+		}
+
+		if !quoted && !not && strings.EqualFold(token, "or") {
+			if len(group) > 0 {
+				flush()
+			}
+			continue
+		}
+
+		if words := Words(token); len(words) > 0 {
+			group = append(group, Term{Words: words, Not: not})
+		}
+	}
+	flush()
+
+	return query
+}
+
+// Words splits text into lowercase words the way Parse does
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,83 @@
+package websearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	word := func(words ...string) Term { return Term{Words: words} }
+	not := func(words ...string) Term { return Term{Words: words, Not: true} }
+
+	tests := []struct {
+		name string
+		in   string
+		want Query
+	}{
+		{"empty", "", nil},
+		{"only spaces", "  \t ", nil},
+		{"one word", "postgres", Query{{word("postgres")}}},
+		{"words are all required", "pgvector index", Query{{word("pgvector"), word("index")}}},
+		{"lowercased", "PgVector", Query{{word("pgvector")}}},
+		{"letters beyond ASCII", "Straße Ünïcode", Query{{word("straße"), word("ünïcode")}}},
+		{"punctuation splits a word into a phrase", "pg-vector", Query{{word("pg", "vector")}}},
+		{"punctuation alone", "...", nil},
+
+		// Quotes
+		{"phrase", `"vector search"`, Query{{word("vector", "search")}}},
+		{"phrase and word", `"vector search" postgres`, Query{{word("vector", "search"), word("postgres")}}},
+		{"unterminated quote runs to the end", `postgres "vector search`, Query{{word("postgres"), word("vector", "search")}}},
+		{"quote inside a word starts a phrase", `pg"vector search"`, Query{{word("pg"), word("vector", "search")}}},
+		{"empty quotes", `""`, nil},
+		{"lone quote", `"`, nil},
+
+		// or
+		{"or", "postgres or sqlite", Query{{word("postgres")}, {word("sqlite")}}},
+		{"OR in capitals", "postgres OR sqlite", Query{{word("postgres")}, {word("sqlite")}}},
+		{"or binds looser than and", "a b or c", Query{{word("a"), word("b")}, {word("c")}}},
+		{"leading or", "or postgres", Query{{word("postgres")}}},
+		{"trailing or", "postgres or", Query{{word("postgres")}}},
+		{"repeated or", "a or or b", Query{{word("a")}, {word("b")}}},
+		{"quoted or is a word", `"or" postgres`, Query{{word("or"), word("postgres")}}},
+		{"or inside a word", "oracle", Query{{word("oracle")}}},
+
+		// -term
+		{"excluded word", "postgres -mysql", Query{{word("postgres"), not("mysql")}}},
+		{"excluded phrase", `postgres -"vector search"`, Query{{word("postgres"), not("vector", "search")}}},
+		{"excluded word first", "-mysql postgres", Query{{not("mysql"), word("postgres")}}},
+		{"hyphen inside a word", "full-text", Query{{word("full", "text")}}},
+		{"excluded or is a word", "postgres -or", Query{{word("postgres"), not("or")}}},
+		{"lone hyphen", "postgres -", Query{{word("postgres")}}},
+
+		// Only excluded terms
+		{"only an excluded word", "-mysql", nil},
+		{"only excluded words", "-mysql -oracle", nil},
+		{"alternative of only excluded words is dropped", "-mysql or postgres", Query{{word("postgres")}}},
+		{"every alternative only excluded", "-mysql or -oracle", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"pg_vector 2.0", []string{"pg", "vector", "2", "0"}},
+		{"日本語 テキスト", []string{"日本語", "テキスト"}},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/AlexTLDR/gator/internal/logging"
+)
+
+// newLogger builds the aggregator's logger from the configured format and
+// level, which may be overridden on the command line
+func newLogger(format, level string) (*slog.Logger, error) {
+	lvl, err := logging.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return logging.New(os.Stdout, format, lvl)
+}
+
+// logFlags are the --log-format and --log-level flags shared by the
+// aggregator commands
+type logFlags struct {
+	format *string
+	level  *string
+}
+
+func addLogFlags(fs *flag.FlagSet) logFlags {
+	return logFlags{
+		format: fs.String("log-format", "", "log output: pretty, text, json or quiet"),
+		level:  fs.String("log-level", "", "minimum log level: debug, info, warn or error"),
+	}
+}
+
+// apply replaces the state's logger if either flag was given
+func (f logFlags) apply(s *state) error {
+	if *f.format == "" && *f.level == "" {
+		return nil
+	}
+
+	format := s.cfg.LogFormat
+	if *f.format != "" {
+		format = *f.format
+	}
+	level := s.cfg.LogLevel
+	if *f.level != "" {
+		level = *f.level
+	}
+
+	logger, err := newLogger(format, level)
+	if err != nil {
+		return err
+	}
+	s.logger = logger
+	return nil
+}
@@ -3,17 +3,24 @@ package main
 import (
 	"database/sql"
 	"log"
+	"log/slog"
 	"os"
 
 	"github.com/AlexTLDR/gator/internal/config"
 	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/metrics"
+	"github.com/AlexTLDR/gator/internal/migrate"
 
 	_ "github.com/lib/pq"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db      database.Store
+	conn    *sql.DB
+	dialect migrate.Dialect
+	cfg     *config.Config
+	logger  *slog.Logger
+	output  outputFormat
 }
 
 func main() {
@@ -22,22 +29,25 @@ func main() {
 		log.Fatalf("error reading config: %v", err)
 	}
 
-	db, err := sql.Open("postgres", cfg.DBURL)
+	db, dbtx, dialect, err := openDatabase(cfg.DBURL)
 	if err != nil {
 		log.Fatalf("error connecting to db: %v", err)
 	}
 	defer db.Close()
 	
-	// Ensure all database tables exist
-	if err := ensureDatabaseMigrations(db); err != nil {
-		log.Fatalf("error ensuring database migrations: %v", err)
+	dbQueries := database.NewStore(metrics.InstrumentDB(dbtx))
+
+	logger, err := newLogger(cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("error configuring logging: %v", err)
 	}
-	
-	dbQueries := database.New(db)
 
 	programState := &state{
-		db:  dbQueries,
-		cfg: &cfg,
+		db:      dbQueries,
+		conn:    db,
+		dialect: dialect,
+		cfg:     &cfg,
+		logger:  logger,
 	}
 
 	cmds := commands{
@@ -48,20 +58,58 @@ func main() {
 	cmds.register("reset", handlerReset)
 	cmds.register("users", handlerUsers)
 	cmds.register("agg", handlerAgg)
+	cmds.register("fetchlog", handlerFetchLog)
+	cmds.register("refresh", handlerRefresh)
 	cmds.register("addfeed", middlewareLoggedIn(handlerAddFeed))
 	cmds.register("feeds", handlerFeeds)
+	cmds.register("fulltext", middlewareLoggedIn(handlerFullText))
 	cmds.register("follow", middlewareLoggedIn(handlerFollow))
 	cmds.register("following", middlewareLoggedIn(handlerFollowing))
 	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
 	cmds.register("browse", middlewareLoggedIn(handlerBrowse))
+	cmds.register("search", middlewareLoggedIn(handlerSearch))
+	cmds.register("read", middlewareLoggedIn(handlerRead))
+	cmds.register("unread", middlewareLoggedIn(handlerUnread))
+	cmds.register("markread", middlewareLoggedIn(handlerMarkRead))
+	cmds.register("save", middlewareLoggedIn(handlerSave))
+	cmds.register("unsave", middlewareLoggedIn(handlerUnsave))
+	cmds.register("saved", middlewareLoggedIn(handlerSaved))
+	cmds.register("migrate", handlerMigrate)
+	cmds.register("timezone", middlewareLoggedIn(handlerTimezone))
+	cmds.register("retention", middlewareLoggedIn(handlerRetention))
+	cmds.register("purge", handlerPurge)
+	cmds.register("bench", handlerBench)
 
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: cli <command> [args...]")
+	output, args, err := extractOutputFlag(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) < 1 {
+		log.Fatal("Usage: cli [--output text|json|jsonl|csv|tsv] <command> [args...]")
 		return
 	}
 
-	cmdName := os.Args[1]
-	cmdArgs := os.Args[2:]
+	cmdName := args[0]
+	cmdArgs := args[1:]
+
+	if output != outputText && !listingCommands[cmdName] && cmdName != "agg" {
+		log.Fatalf("%s only prints text; --output %s works with: users, feeds, following, fetchlog, browse, search, saved and agg --once", cmdName, output)
+	}
+	programState.output = output
+
+	// The migrate command manages the schema itself; everything else needs
+	// it up to date, and only migrates on its own when configured to
+	if cmdName != "migrate" {
+		if cfg.AutoMigrate {
+			err = runMigrations(db, dialect)
+		} else {
+			err = checkSchema(db, dialect)
+		}
+		if err != nil {
+			log.Fatalf("error checking database schema: %v", err)
+		}
+	}
 
 	err = cmds.run(programState, command{Name: cmdName, Args: cmdArgs})
 	if err != nil {
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// outputFormat is how listing commands print what they list, picked with
+// the global --output option
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputJSON  outputFormat = "json"
+	outputJSONL outputFormat = "jsonl"
+	outputCSV   outputFormat = "csv"
+	outputTSV   outputFormat = "tsv"
+)
+
+// listingCommands can print in any output format; every other command only
+// prints text, except agg --once, which can print its summary in any format
+var listingCommands = map[string]bool{
+	"users":     true,
+	"feeds":     true,
+	"following": true,
+	"fetchlog":  true,
+	"browse":    true,
+	"search":    true,
+	"saved":     true,
+}
+
+func parseOutputFormat(value string) (outputFormat, error) {
+	switch f := outputFormat(value); f {
+	case outputText, outputJSON, outputJSONL, outputCSV, outputTSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid --output %q: use text, json, jsonl, csv or tsv", value)
+}
+
+// extractOutputFlag takes the global --output option out of the command
+// line, where it may come before or after the command name, and returns the
+// format along with the remaining arguments
+func extractOutputFlag(args []string) (outputFormat, []string, error) {
+	format := outputText
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		value, ok := "", false
+		switch {
+		case arg == "--output" || arg == "-output":
+			if i+1 == len(args) {
+				return "", nil, fmt.Errorf("--output needs a format: text, json, jsonl, csv or tsv")
+			}
+			value, ok = args[i+1], true
+			i++
+		case strings.HasPrefix(arg, "--output="):
+			value, ok = strings.TrimPrefix(arg, "--output="), true
+		case strings.HasPrefix(arg, "-output="):
+			value, ok = strings.TrimPrefix(arg, "-output="), true
+		}
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+
+		f, err := parseOutputFormat(value)
+		if err != nil {
+			return "", nil, err
+		}
+		format = f
+	}
+	return format, rest, nil
+}
+
+// printRecords writes records in a machine-readable format. Records are
+// structs whose json tags name their fields; CSV and TSV use the same names
+// for their header row. Times are written in UTC as RFC 3339, and nil
+// pointers as null, or as empty fields in CSV and TSV.
+func printRecords[T any](w io.Writer, format outputFormat, records []T) error {
+	switch format {
+	case outputJSON:
+		if records == nil {
+			records = []T{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case outputJSONL:
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputCSV, outputTSV:
+		typ := reflect.TypeFor[T]()
+		header := make([]string, typ.NumField())
+		for i := range header {
+			header[i], _, _ = strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		}
+
+		rows := [][]string{header}
+		for _, r := range records {
+			v := reflect.ValueOf(r)
+			row := make([]string, v.NumField())
+			for i := range row {
+				row[i] = formatField(v.Field(i))
+			}
+			rows = append(rows, row)
+		}
+
+		if format == outputTSV {
+			return writeTSV(w, rows)
+		}
+		cw := csv.NewWriter(w)
+		cw.WriteAll(rows)
+		return cw.Error()
+	}
+
+	return fmt.Errorf("%s output isn't supported here", format)
+}
+
+// formatField renders a record field for CSV and TSV
+func formatField(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return x.String()
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// tsvEscaper escapes the characters that would break a TSV row, the way
+// Postgres's COPY text format does
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func writeTSV(w io.Writer, rows [][]string) error {
+	for _, row := range rows {
+		for i, field := range row {
+			row[i] = tsvEscaper.Replace(field)
+		}
+		if _, err := io.WriteString(w, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timeField returns a nullable time for a record, in UTC
+func timeField(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// stringField returns a nullable string for a record
+func stringField(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExtractOutputFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     outputFormat
+		wantRest []string
+		wantErr  bool
+	}{
+		{"absent", []string{"browse", "10"}, outputText, []string{"browse", "10"}, false},
+		{"before the command", []string{"--output", "json", "browse", "10"}, outputJSON, []string{"browse", "10"}, false},
+		{"after the command", []string{"browse", "10", "--output", "csv"}, outputCSV, []string{"browse", "10"}, false},
+		{"with =", []string{"feeds", "--output=tsv"}, outputTSV, []string{"feeds"}, false},
+		{"with one dash", []string{"-output", "jsonl", "fetchlog"}, outputJSONL, []string{"fetchlog"}, false},
+		{"with one dash and =", []string{"fetchlog", "-output=text"}, outputText, []string{"fetchlog"}, false},
+		{"last one wins", []string{"--output", "json", "users", "--output", "csv"}, outputCSV, []string{"users"}, false},
+		{"after --", []string{"search", "--", "--output", "json"}, outputText, []string{"search", "--", "--output", "json"}, false},
+		{"unknown format", []string{"users", "--output", "xml"}, "", nil, true},
+		{"empty format", []string{"users", "--output="}, "", nil, true},
+		{"missing format", []string{"users", "--output"}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := extractOutputFlag(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractOutputFlag(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if got != tt.want || !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("extractOutputFlag(%q) = %q, %q, want %q, %q", tt.args, got, rest, tt.want, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestFormatField(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	at := time.Date(2026, 3, 1, 13, 0, 0, 500000000, cet)
+	status := int32(404)
+	msg := "not found"
+	var noTime *time.Time
+	var noStatus *int32
+
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"time, in UTC", at, "2026-03-01T12:00:00.5Z"},
+		{"time pointer", &at, "2026-03-01T12:00:00.5Z"},
+		{"nil time pointer", noTime, ""},
+		{"uuid", uuid.MustParse("00000000-0000-0000-0000-000000000001"), "00000000-0000-0000-0000-000000000001"},
+		{"string", "a, b", "a, b"},
+		{"string pointer", &msg, "not found"},
+		{"bool", true, "true"},
+		{"int64", int64(12345), "12345"},
+		{"int32 pointer", &status, "404"},
+		{"nil int32 pointer", noStatus, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatField(reflect.ValueOf(tt.in)); got != tt.want {
+				t.Errorf("formatField(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPrintRecords checks the exact output of every machine-readable format
+// for the record type of every listing command
+func TestPrintRecords(t *testing.T) {
+	feedID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	id := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := time.Date(2026, 3, 1, 12, 0, 1, 500000000, time.UTC)
+	refused := "connection refused"
+
+	tests := []struct {
+		name  string
+		print func(w io.Writer, format outputFormat) error
+		json  string
+		jsonl string
+		csv   string
+		tsv   string
+	}{
+		{
+			name: "users",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []userRecord{{Name: "alice", Current: true, CreatedAt: at}})
+			},
+			json: `[
+  {
+    "name": "alice",
+    "current": true,
+    "timezone": "",
+    "created_at": "2026-03-01T12:00:00Z"
+  }
+]
+`,
+			jsonl: `{"name":"alice","current":true,"timezone":"","created_at":"2026-03-01T12:00:00Z"}` + "\n",
+			csv:   "name,current,timezone,created_at\nalice,true,,2026-03-01T12:00:00Z\n",
+			tsv:   "name\tcurrent\ttimezone\tcreated_at\nalice\ttrue\t\t2026-03-01T12:00:00Z\n",
+		},
+		{
+			name: "feeds",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []feedRecord{{ID: feedID, Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", AddedBy: "alice", CreatedAt: at}})
+			},
+			json: `[
+  {
+    "id": "00000000-0000-0000-0000-000000000001",
+    "name": "Go Blog",
+    "url": "https://go.dev/blog/feed.atom",
+    "added_by": "alice",
+    "full_text": false,
+    "created_at": "2026-03-01T12:00:00Z"
+  }
+]
+`,
+			jsonl: `{"id":"00000000-0000-0000-0000-000000000001","name":"Go Blog","url":"https://go.dev/blog/feed.atom","added_by":"alice","full_text":false,"created_at":"2026-03-01T12:00:00Z"}` + "\n",
+			csv:   "id,name,url,added_by,full_text,created_at\n00000000-0000-0000-0000-000000000001,Go Blog,https://go.dev/blog/feed.atom,alice,false,2026-03-01T12:00:00Z\n",
+			tsv:   "id\tname\turl\tadded_by\tfull_text\tcreated_at\n00000000-0000-0000-0000-000000000001\tGo Blog\thttps://go.dev/blog/feed.atom\talice\tfalse\t2026-03-01T12:00:00Z\n",
+		},
+		{
+			name: "following",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []followRecord{{FeedID: feedID, FeedName: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom", FollowedAt: at, UnreadCount: 3}})
+			},
+			json: `[
+  {
+    "feed_id": "00000000-0000-0000-0000-000000000001",
+    "feed_name": "Go Blog",
+    "feed_url": "https://go.dev/blog/feed.atom",
+    "followed_at": "2026-03-01T12:00:00Z",
+    "unread_count": 3
+  }
+]
+`,
+			jsonl: `{"feed_id":"00000000-0000-0000-0000-000000000001","feed_name":"Go Blog","feed_url":"https://go.dev/blog/feed.atom","followed_at":"2026-03-01T12:00:00Z","unread_count":3}` + "\n",
+			csv:   "feed_id,feed_name,feed_url,followed_at,unread_count\n00000000-0000-0000-0000-000000000001,Go Blog,https://go.dev/blog/feed.atom,2026-03-01T12:00:00Z,3\n",
+			tsv:   "feed_id\tfeed_name\tfeed_url\tfollowed_at\tunread_count\n00000000-0000-0000-0000-000000000001\tGo Blog\thttps://go.dev/blog/feed.atom\t2026-03-01T12:00:00Z\t3\n",
+		},
+		{
+			name: "fetchlog",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []fetchRecord{{ID: id, FeedID: feedID, FeedName: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom", StartedAt: at, FinishedAt: &later, Error: &refused}})
+			},
+			json: `[
+  {
+    "id": "00000000-0000-0000-0000-000000000002",
+    "feed_id": "00000000-0000-0000-0000-000000000001",
+    "feed_name": "Go Blog",
+    "feed_url": "https://go.dev/blog/feed.atom",
+    "started_at": "2026-03-01T12:00:00Z",
+    "finished_at": "2026-03-01T12:00:01.5Z",
+    "http_status": null,
+    "bytes": 0,
+    "items_seen": 0,
+    "items_inserted": 0,
+    "error": "connection refused"
+  }
+]
+`,
+			jsonl: `{"id":"00000000-0000-0000-0000-000000000002","feed_id":"00000000-0000-0000-0000-000000000001","feed_name":"Go Blog","feed_url":"https://go.dev/blog/feed.atom","started_at":"2026-03-01T12:00:00Z","finished_at":"2026-03-01T12:00:01.5Z","http_status":null,"bytes":0,"items_seen":0,"items_inserted":0,"error":"connection refused"}` + "\n",
+			csv:   "id,feed_id,feed_name,feed_url,started_at,finished_at,http_status,bytes,items_seen,items_inserted,error\n00000000-0000-0000-0000-000000000002,00000000-0000-0000-0000-000000000001,Go Blog,https://go.dev/blog/feed.atom,2026-03-01T12:00:00Z,2026-03-01T12:00:01.5Z,,0,0,0,connection refused\n",
+			tsv:   "id\tfeed_id\tfeed_name\tfeed_url\tstarted_at\tfinished_at\thttp_status\tbytes\titems_seen\titems_inserted\terror\n00000000-0000-0000-0000-000000000002\t00000000-0000-0000-0000-000000000001\tGo Blog\thttps://go.dev/blog/feed.atom\t2026-03-01T12:00:00Z\t2026-03-01T12:00:01.5Z\t\t0\t0\t0\tconnection refused\n",
+		},
+		{
+			name: "browse",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []postRecord{{
+					ID:                  id,
+					Title:               `Go 1.24, "released"`,
+					URL:                 "https://go.dev/blog/go1.24",
+					FeedID:              feedID,
+					FeedName:            "Go Blog",
+					PublishedAtInferred: true,
+					FetchedAt:           at,
+					Authors:             "Ann, Bob",
+					Tags:                "go",
+					Description:         "Line one\nLine two",
+					Cursor:              "abc",
+				}})
+			},
+			json: `[
+  {
+    "id": "00000000-0000-0000-0000-000000000002",
+    "title": "Go 1.24, \"released\"",
+    "url": "https://go.dev/blog/go1.24",
+    "feed_id": "00000000-0000-0000-0000-000000000001",
+    "feed_name": "Go Blog",
+    "published_at": null,
+    "published_at_inferred": true,
+    "fetched_at": "2026-03-01T12:00:00Z",
+    "authors": "Ann, Bob",
+    "tags": "go",
+    "read": false,
+    "description": "Line one\nLine two",
+    "content": "",
+    "cursor": "abc"
+  }
+]
+`,
+			jsonl: `{"id":"00000000-0000-0000-0000-000000000002","title":"Go 1.24, \"released\"","url":"https://go.dev/blog/go1.24","feed_id":"00000000-0000-0000-0000-000000000001","feed_name":"Go Blog","published_at":null,"published_at_inferred":true,"fetched_at":"2026-03-01T12:00:00Z","authors":"Ann, Bob","tags":"go","read":false,"description":"Line one\nLine two","content":"","cursor":"abc"}` + "\n",
+			csv:   "id,title,url,feed_id,feed_name,published_at,published_at_inferred,fetched_at,authors,tags,read,description,content,cursor\n00000000-0000-0000-0000-000000000002,\"Go 1.24, \"\"released\"\"\",https://go.dev/blog/go1.24,00000000-0000-0000-0000-000000000001,Go Blog,,true,2026-03-01T12:00:00Z,\"Ann, Bob\",go,false,\"Line one\nLine two\",,abc\n",
+			tsv:   "id\ttitle\turl\tfeed_id\tfeed_name\tpublished_at\tpublished_at_inferred\tfetched_at\tauthors\ttags\tread\tdescription\tcontent\tcursor\n00000000-0000-0000-0000-000000000002\tGo 1.24, \"released\"\thttps://go.dev/blog/go1.24\t00000000-0000-0000-0000-000000000001\tGo Blog\t\ttrue\t2026-03-01T12:00:00Z\tAnn, Bob\tgo\tfalse\tLine one\\nLine two\t\tabc\n",
+		},
+		{
+			name: "search",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []searchRecord{{ID: id, Title: "Go 1.24", URL: "https://go.dev/blog/go1.24", FeedName: "Go Blog", PublishedAt: &at, Snippet: "faster\tmaps"}})
+			},
+			json: `[
+  {
+    "id": "00000000-0000-0000-0000-000000000002",
+    "title": "Go 1.24",
+    "url": "https://go.dev/blog/go1.24",
+    "feed_name": "Go Blog",
+    "published_at": "2026-03-01T12:00:00Z",
+    "published_at_inferred": false,
+    "snippet": "faster\tmaps"
+  }
+]
+`,
+			jsonl: `{"id":"00000000-0000-0000-0000-000000000002","title":"Go 1.24","url":"https://go.dev/blog/go1.24","feed_name":"Go Blog","published_at":"2026-03-01T12:00:00Z","published_at_inferred":false,"snippet":"faster\tmaps"}` + "\n",
+			csv:   "id,title,url,feed_name,published_at,published_at_inferred,snippet\n00000000-0000-0000-0000-000000000002,Go 1.24,https://go.dev/blog/go1.24,Go Blog,2026-03-01T12:00:00Z,false,faster\tmaps\n",
+			tsv:   "id\ttitle\turl\tfeed_name\tpublished_at\tpublished_at_inferred\tsnippet\n00000000-0000-0000-0000-000000000002\tGo 1.24\thttps://go.dev/blog/go1.24\tGo Blog\t2026-03-01T12:00:00Z\tfalse\tfaster\\tmaps\n",
+		},
+		{
+			name: "saved",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []savedRecord{{ID: id, Title: "Go 1.24", URL: "https://go.dev/blog/go1.24", FeedName: "Go Blog", PublishedAt: &at, SavedAt: later, Tags: "go, later", Note: `C:\notes`}})
+			},
+			json: `[
+  {
+    "id": "00000000-0000-0000-0000-000000000002",
+    "title": "Go 1.24",
+    "url": "https://go.dev/blog/go1.24",
+    "feed_name": "Go Blog",
+    "published_at": "2026-03-01T12:00:00Z",
+    "saved_at": "2026-03-01T12:00:01.5Z",
+    "tags": "go, later",
+    "note": "C:\\notes"
+  }
+]
+`,
+			jsonl: `{"id":"00000000-0000-0000-0000-000000000002","title":"Go 1.24","url":"https://go.dev/blog/go1.24","feed_name":"Go Blog","published_at":"2026-03-01T12:00:00Z","saved_at":"2026-03-01T12:00:01.5Z","tags":"go, later","note":"C:\\notes"}` + "\n",
+			csv:   "id,title,url,feed_name,published_at,saved_at,tags,note\n00000000-0000-0000-0000-000000000002,Go 1.24,https://go.dev/blog/go1.24,Go Blog,2026-03-01T12:00:00Z,2026-03-01T12:00:01.5Z,\"go, later\",C:\\notes\n",
+			tsv:   "id\ttitle\turl\tfeed_name\tpublished_at\tsaved_at\ttags\tnote\n00000000-0000-0000-0000-000000000002\tGo 1.24\thttps://go.dev/blog/go1.24\tGo Blog\t2026-03-01T12:00:00Z\t2026-03-01T12:00:01.5Z\tgo, later\tC:\\\\notes\n",
+		},
+		{
+			name: "agg --once",
+			print: func(w io.Writer, format outputFormat) error {
+				return printRecords(w, format, []aggSummary{{Feeds: 3, Fetched: 2, Failed: 1, ItemsSeen: 40, NewPosts: 5}})
+			},
+			json: `[
+  {
+    "feeds": 3,
+    "fetched": 2,
+    "failed": 1,
+    "items_seen": 40,
+    "new_posts": 5
+  }
+]
+`,
+			jsonl: `{"feeds":3,"fetched":2,"failed":1,"items_seen":40,"new_posts":5}` + "\n",
+			csv:   "feeds,fetched,failed,items_seen,new_posts\n3,2,1,40,5\n",
+			tsv:   "feeds\tfetched\tfailed\titems_seen\tnew_posts\n3\t2\t1\t40\t5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		for format, want := range map[outputFormat]string{
+			outputJSON:  tt.json,
+			outputJSONL: tt.jsonl,
+			outputCSV:   tt.csv,
+			outputTSV:   tt.tsv,
+		} {
+			t.Run(tt.name+" as "+string(format), func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := tt.print(&buf, format); err != nil {
+					t.Fatal(err)
+				}
+				if got := buf.String(); got != want {
+					t.Errorf("got:\n%s\nwant:\n%s", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestPrintRecordsEmpty(t *testing.T) {
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{outputJSON, "[]\n"},
+		{outputJSONL, ""},
+		{outputCSV, "name,current,timezone,created_at\n"},
+		{outputTSV, "name\tcurrent\ttimezone\tcreated_at\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printRecords[userRecord](&buf, tt.format, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printRecords(%s, nil) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintRecordsText(t *testing.T) {
+	if err := printRecords(io.Discard, outputText, []userRecord{{Name: "alice"}}); err == nil {
+		t.Error("printRecords accepted text output")
+	}
+}
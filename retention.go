@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/logging"
+	"github.com/AlexTLDR/gator/internal/metrics"
+)
+
+// purgeInterval is how often the aggregator applies the retention policy
+const purgeInterval = time.Hour
+
+// errExpired is returned by savePost for items its feed's retention policy
+// would purge straight away
+var errExpired = errors.New("post is outside the feed's retention policy")
+
+// retentionPolicy limits how many posts of a feed are kept. A zero field
+// means no limit.
+type retentionPolicy struct {
+	Days int
+	Keep int
+}
+
+// feedRetention returns the policy for feed: its own limits where it has
+// them, and the config's otherwise
+func feedRetention(s *state, feed database.Feed) retentionPolicy {
+	policy := retentionPolicy{Days: s.cfg.RetentionDays, Keep: s.cfg.RetentionKeep}
+	if feed.RetentionDays.Valid {
+		policy.Days = int(feed.RetentionDays.Int32)
+	}
+	if feed.RetentionKeep.Valid {
+		policy.Keep = int(feed.RetentionKeep.Int32)
+	}
+	return policy
+}
+
+func (p retentionPolicy) unlimited() bool {
+	return p.Days <= 0 && p.Keep <= 0
+}
+
+// cutoff is the publication date before which posts expire
+func (p retentionPolicy) cutoff(now time.Time) sql.NullTime {
+	if p.Days <= 0 {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: now.AddDate(0, 0, -p.Days), Valid: true}
+}
+
+func (p retentionPolicy) keep() sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(p.Keep), Valid: p.Keep > 0}
+}
+
+func (p retentionPolicy) String() string {
+	switch {
+	case p.Days > 0 && p.Keep > 0:
+		return fmt.Sprintf("the newest %d posts from the last %d days", p.Keep, p.Days)
+	case p.Days > 0:
+		return fmt.Sprintf("posts from the last %d days", p.Days)
+	case p.Keep > 0:
+		return fmt.Sprintf("the newest %d posts", p.Keep)
+	default:
+		return "every post"
+	}
+}
+
+// expiresImmediately reports whether a post of feed published at
+// publishedAt would be purged as soon as it was saved. Saving such posts
+// would only have them purged and fetched again, over and over, for as long
+// as the feed lists them. An inferred date is only a guess, so a post with
+// one is never turned away.
+func expiresImmediately(ctx context.Context, s *state, feed database.Feed, publishedAt sql.NullTime, inferred bool) (bool, error) {
+	policy := feedRetention(s, feed)
+	if policy.unlimited() || !publishedAt.Valid || inferred {
+		return false, nil
+	}
+
+	if cutoff := policy.cutoff(time.Now().UTC()); cutoff.Valid && publishedAt.Time.Before(cutoff.Time) {
+		return true, nil
+	}
+
+	if policy.Keep > 0 {
+		newer, err := s.db.CountNewerPosts(ctx, database.CountNewerPostsParams{
+			FeedID:      feed.ID,
+			PublishedAt: publishedAt,
+		})
+		if err != nil {
+			return false, err
+		}
+		return newer >= int64(policy.Keep), nil
+	}
+
+	return false, nil
+}
+
+// purgeResult is how many posts a feed lost, or would lose, to its retention
+// policy
+type purgeResult struct {
+	Feed   database.Feed
+	Policy retentionPolicy
+	Posts  int64
+}
+
+// purgeFeeds applies the retention policy to each of feeds, or with dryRun
+// only counts the posts it would delete. Feeds without limits are left out
+// of the results.
+func purgeFeeds(ctx context.Context, s *state, feeds []database.Feed, dryRun bool) ([]purgeResult, error) {
+	now := time.Now().UTC()
+
+	var results []purgeResult
+	for _, feed := range feeds {
+		policy := feedRetention(s, feed)
+		if policy.unlimited() {
+			continue
+		}
+
+		var n int64
+		var err error
+		if dryRun {
+			n, err = s.db.CountExpiredPosts(ctx, database.CountExpiredPostsParams{
+				FeedID: feed.ID,
+				Cutoff: policy.cutoff(now),
+				Keep:   policy.keep(),
+			})
+		} else {
+			n, err = s.db.DeleteExpiredPosts(ctx, database.DeleteExpiredPostsParams{
+				FeedID: feed.ID,
+				Cutoff: policy.cutoff(now),
+				Keep:   policy.keep(),
+			})
+			metrics.PostsPurged(int(n))
+		}
+		if err != nil {
+			return results, fmt.Errorf("couldn't purge posts of '%s': %w", feed.Name, err)
+		}
+
+		results = append(results, purgeResult{Feed: feed, Policy: policy, Posts: n})
+	}
+	return results, nil
+}
+
+// purgeExpired applies the retention policy to every feed on behalf of the
+// aggregator, logging rather than returning failures
+func purgeExpired(ctx context.Context, s *state) {
+	feeds, err := s.db.GetAllFeeds(ctx)
+	if err != nil {
+		s.logger.Warn("Could not list feeds to purge", "error", err)
+		return
+	}
+
+	results, err := purgeFeeds(ctx, s, feeds, false)
+	if err != nil {
+		s.logger.Warn("Could not purge expired posts", "error", err)
+	}
+
+	var total int64
+	for _, r := range results {
+		if r.Posts > 0 {
+			s.logger.Debug("Purged expired posts", "feed_id", r.Feed.ID, "feed_name", r.Feed.Name, "posts", r.Posts)
+		}
+		total += r.Posts
+	}
+	if total > 0 {
+		s.logger.Info("Purged expired posts", logging.Icon("🧹"), "posts", total)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/config"
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/memstore"
+
+	"github.com/google/uuid"
+)
+
+func TestExpiresImmediately(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	db := memstore.New()
+
+	user, err := db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := db.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "news", Url: "https://news.example/feed.xml", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := db.CreatePost(ctx, database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Title:       "New post",
+			Url:         "https://news.example/" + uuid.NewString(),
+			PublishedAt: sql.NullTime{Time: now, Valid: true},
+			FeedID:      feed.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	at := func(d time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(d), Valid: true} }
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		publishedAt sql.NullTime
+		inferred    bool
+		want        bool
+	}{
+		{"no limits", config.Config{}, at(-365 * 24 * time.Hour), false, false},
+		{"within the days", config.Config{RetentionDays: 7}, at(-24 * time.Hour), false, false},
+		{"past the days", config.Config{RetentionDays: 7}, at(-8 * 24 * time.Hour), false, true},
+		{"past the days by an inferred date", config.Config{RetentionDays: 7}, at(-8 * 24 * time.Hour), true, false},
+		{"no date", config.Config{RetentionDays: 7}, sql.NullTime{}, false, false},
+		{"among the newest", config.Config{RetentionKeep: 3}, at(-time.Hour), false, false},
+		{"older than the newest", config.Config{RetentionKeep: 2}, at(-time.Hour), false, true},
+		{"older than the newest by an inferred date", config.Config{RetentionKeep: 2}, at(-time.Hour), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &state{db: db, cfg: &tt.cfg}
+			got, err := expiresImmediately(ctx, s, feed, tt.publishedAt, tt.inferred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expiresImmediately = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
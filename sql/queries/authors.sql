@@ -0,0 +1,16 @@
+-- name: UpsertAuthor :one
+INSERT INTO authors (id, created_at, name, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (name, email) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: AddPostAuthor :exec
+INSERT INTO post_authors (post_id, author_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetAuthorsForPost :many
+SELECT a.* FROM authors a
+JOIN post_authors pa ON a.id = pa.author_id
+WHERE pa.post_id = $1
+ORDER BY a.name;
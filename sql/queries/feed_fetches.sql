@@ -0,0 +1,24 @@
+-- name: CreateFeedFetch :one
+INSERT INTO feed_fetches (id, feed_id, started_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: FinishFeedFetch :exec
+UPDATE feed_fetches
+SET finished_at = $2,
+    http_status = $3,
+    bytes = $4,
+    items_seen = $5,
+    items_inserted = $6,
+    error = $7
+WHERE id = $1;
+
+-- name: GetFeedFetches :many
+SELECT ff.id, ff.feed_id, ff.started_at, ff.finished_at, ff.http_status, ff.bytes,
+       ff.items_seen, ff.items_inserted, ff.error,
+       f.name as feed_name, f.url as feed_url
+FROM feed_fetches ff
+JOIN feeds f ON ff.feed_id = f.id
+WHERE sqlc.narg('feed_id')::uuid IS NULL OR ff.feed_id = sqlc.narg('feed_id')
+ORDER BY ff.started_at DESC
+LIMIT sqlc.arg('limit');
@@ -17,7 +17,15 @@ JOIN feeds f ON ff.feed_id = f.id;
 -- name: GetFeedFollowsForUser :many
 SELECT ff.id, ff.created_at, ff.updated_at, ff.user_id, ff.feed_id, 
        u.name as user_name, 
-       f.name as feed_name
+       f.name as feed_name,
+       f.url as feed_url,
+       (
+           SELECT COUNT(*) FROM posts p
+           WHERE p.feed_id = ff.feed_id AND NOT EXISTS (
+               SELECT 1 FROM post_reads pr
+               WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+           )
+       ) as unread_count
 FROM feed_follows ff
 JOIN users u ON ff.user_id = u.id
 JOIN feeds f ON ff.feed_id = f.id
@@ -26,7 +26,7 @@ DELETE FROM feeds WHERE id = $1;
 DELETE FROM feeds WHERE user_id = $1;
 
 -- name: GetFeedsWithUsers :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, u.name as user_name
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, f.fetch_full_article, u.name as user_name
 FROM feeds f
 JOIN users u ON f.user_id = u.id
 ORDER BY f.created_at DESC;
@@ -39,4 +39,35 @@ WHERE id = $2;
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: SetFeedFetchFullArticle :exec
+UPDATE feeds
+SET fetch_full_article = $1, updated_at = $2
+WHERE id = $3;
+
+
+-- name: GetFeedsByName :many
+SELECT * FROM feeds WHERE name = $1;
+
+
+-- name: GetFeedsDueForFetch :many
+SELECT * FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at <= $1
+ORDER BY last_fetched_at ASC NULLS FIRST;
+
+
+-- name: CountFeedsDueForFetch :one
+SELECT COUNT(*) FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at <= $1;
+
+
+-- name: GetAllFeeds :many
+SELECT * FROM feeds
+ORDER BY name, url;
+
+
+-- name: SetFeedRetention :exec
+UPDATE feeds
+SET retention_days = $1, retention_keep = $2, updated_at = $3
+WHERE id = $4;
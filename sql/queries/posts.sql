@@ -1,25 +1,137 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING *;
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, published_at_inferred, feed_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, published_at_inferred;
 
 -- name: GetPostsForUser :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, 
-       p.feed_id, f.name as feed_name
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.content, p.published_at, 
+       p.published_at_inferred, p.feed_id, f.name as feed_name,
+       COALESCE((
+           SELECT string_agg(c.name, ', ' ORDER BY c.name)
+           FROM post_categories pc
+           JOIN categories c ON pc.category_id = c.id
+           WHERE pc.post_id = p.id
+       ), '')::text as categories,
+       COALESCE((
+           SELECT string_agg(a.name, ', ' ORDER BY a.name)
+           FROM post_authors pa
+           JOIN authors a ON pa.author_id = a.id
+           WHERE pa.post_id = p.id
+       ), '')::text as authors,
+       EXISTS (
+           SELECT 1 FROM post_reads pr
+           WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+       ) as read
 FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN feed_follows ff ON f.id = ff.feed_id
-WHERE ff.user_id = $1
-ORDER BY p.published_at DESC
-LIMIT $2;
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND (NOT sqlc.arg('unread_only')::bool OR NOT EXISTS (
+      SELECT 1 FROM post_reads pr
+      WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+  ))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_categories pc
+      JOIN categories c ON pc.category_id = c.id
+      WHERE pc.post_id = p.id AND c.name = lower(sqlc.narg('tag'))
+  ))
+  AND (sqlc.narg('author')::text IS NULL OR EXISTS (
+      SELECT 1 FROM post_authors pa
+      JOIN authors a ON pa.author_id = a.id
+      WHERE pa.post_id = p.id
+        AND (lower(a.name) = lower(sqlc.narg('author')) OR lower(a.email) = lower(sqlc.narg('author')))
+  ))
+  AND (sqlc.narg('feed_id')::uuid IS NULL OR p.feed_id = sqlc.narg('feed_id'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR p.published_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR p.published_at < sqlc.narg('until'))
+  AND (NOT sqlc.arg('saved_only')::bool OR EXISTS (
+      SELECT 1 FROM saved_posts s
+      WHERE s.post_id = p.id AND s.user_id = ff.user_id
+  ))
+  -- Only posts after the cursor (before_published_at, before_id) in the
+  -- published order below, where a NULL date sorts first
+  AND (sqlc.narg('before_id')::uuid IS NULL
+       OR p.published_at < sqlc.narg('before_published_at')::timestamptz
+       OR (p.published_at = sqlc.narg('before_published_at') AND p.id < sqlc.narg('before_id'))
+       OR (sqlc.narg('before_published_at') IS NULL
+           AND (p.published_at IS NOT NULL OR p.id < sqlc.narg('before_id'))))
+-- sort is 'published', 'fetched' (newest stored first) or 'feed' (by feed
+-- name, then published)
+ORDER BY CASE WHEN sqlc.arg('sort')::text = 'feed' THEN f.name END ASC,
+         CASE WHEN sqlc.arg('sort') = 'fetched' THEN p.created_at END DESC,
+         p.published_at DESC NULLS FIRST, p.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: GetPostByURL :one
-SELECT * FROM posts
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, published_at_inferred FROM posts
 WHERE url = $1;
 
+-- name: GetPostsByIDPrefix :many
+-- Posts of feeds the user follows whose ID starts with a prefix, given as the
+-- smallest and largest IDs with it so that the primary key index is used
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.published_at_inferred
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND p.id BETWEEN sqlc.arg('lower')::uuid AND sqlc.arg('upper')::uuid
+ORDER BY p.id
+LIMIT 2;
+
 -- name: DeletePostsByFeedID :exec
+-- Saved posts are kept
 DELETE FROM posts
-WHERE feed_id = $1;
+WHERE feed_id = $1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = posts.id);
 
 -- name: GetPostsCount :one
-SELECT COUNT(*) FROM posts;
\ No newline at end of file
+SELECT COUNT(*) FROM posts;
+
+-- name: UpdatePostContent :exec
+UPDATE posts
+SET content = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: CountNewerPosts :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1 AND (published_at IS NULL OR published_at > $2);
+
+-- name: CountExpiredPosts :one
+SELECT COUNT(*) FROM posts p
+WHERE p.feed_id = sqlc.arg('feed_id')
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = p.id)
+  AND ((sqlc.narg('cutoff')::timestamptz IS NOT NULL AND p.published_at < sqlc.narg('cutoff'))
+    OR (sqlc.narg('keep')::int IS NOT NULL AND p.id IN (
+        SELECT k.id FROM posts k
+        WHERE k.feed_id = sqlc.arg('feed_id')
+        ORDER BY k.published_at DESC NULLS FIRST, k.id DESC
+        OFFSET sqlc.narg('keep')
+    )));
+
+-- name: DeleteExpiredPosts :execrows
+DELETE FROM posts p
+WHERE p.feed_id = sqlc.arg('feed_id')
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = p.id)
+  AND ((sqlc.narg('cutoff')::timestamptz IS NOT NULL AND p.published_at < sqlc.narg('cutoff'))
+    OR (sqlc.narg('keep')::int IS NOT NULL AND p.id IN (
+        SELECT k.id FROM posts k
+        WHERE k.feed_id = sqlc.arg('feed_id')
+        ORDER BY k.published_at DESC NULLS FIRST, k.id DESC
+        OFFSET sqlc.narg('keep')
+    )));
+
+-- name: SearchPosts :many
+SELECT p.id, p.title, p.url, p.published_at, p.published_at_inferred, f.name AS feed_name,
+       ts_headline('english', coalesce(p.content, p.description, p.title),
+           websearch_to_tsquery('english', sqlc.arg('query')),
+           'StartSel=⟪, StopSel=⟫, MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=" … "'
+       )::text AS snippet
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+  AND p.search @@ websearch_to_tsquery('english', sqlc.arg('query'))
+  AND (sqlc.narg('feed_id')::uuid IS NULL OR p.feed_id = sqlc.narg('feed_id'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR p.published_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR p.published_at < sqlc.narg('until'))
+ORDER BY ts_rank_cd(p.search, websearch_to_tsquery('english', sqlc.arg('query'))) DESC, p.published_at DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,67 @@
+-- name: CreateSavedPost :one
+INSERT INTO saved_posts (id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note)
+SELECT sqlc.arg('id'), sqlc.arg('user_id'), p.id, sqlc.arg('created_at'), sqlc.arg('created_at'),
+       p.url, p.title, f.name, p.published_at, p.description, p.content, sqlc.arg('note')
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+WHERE p.id = sqlc.arg('post_id')
+RETURNING *;
+
+-- name: GetSavedPost :one
+SELECT * FROM saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPostByURL :one
+SELECT * FROM saved_posts
+WHERE user_id = $1 AND url = $2;
+
+-- name: GetSavedPostsByIDPrefix :many
+-- The user's saves of posts whose ID starts with a prefix, given as the
+-- smallest and largest IDs with it so that the (user_id, post_id) index is
+-- used
+SELECT * FROM saved_posts
+WHERE user_id = sqlc.arg('user_id')
+  AND post_id BETWEEN sqlc.arg('lower')::uuid AND sqlc.arg('upper')::uuid
+ORDER BY post_id
+LIMIT 2;
+
+-- name: SetSavedPostNote :exec
+UPDATE saved_posts
+SET note = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: DeleteSavedPost :exec
+DELETE FROM saved_posts
+WHERE id = $1;
+
+-- name: AddSavedPostTag :exec
+INSERT INTO saved_post_tags (saved_post_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveSavedPostTag :exec
+DELETE FROM saved_post_tags
+WHERE saved_post_id = $1 AND tag = $2;
+
+-- name: GetSavedPosts :many
+SELECT s.id, s.user_id, s.post_id, s.created_at, s.updated_at, s.url, s.title, s.feed_name,
+       s.published_at, s.description, s.content, s.note,
+       COALESCE((
+           SELECT string_agg(t.tag, ', ' ORDER BY t.tag)
+           FROM saved_post_tags t
+           WHERE t.saved_post_id = s.id
+       ), '')::text as tags
+FROM saved_posts s
+WHERE s.user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+      SELECT 1 FROM saved_post_tags t
+      WHERE t.saved_post_id = s.id AND t.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('feed_name')::text IS NULL OR lower(s.feed_name) = lower(sqlc.narg('feed_name')))
+  AND (sqlc.narg('text')::text IS NULL
+       OR s.title ILIKE '%' || sqlc.narg('text') || '%'
+       OR s.note ILIKE '%' || sqlc.narg('text') || '%')
+  AND (sqlc.narg('since')::timestamptz IS NULL OR s.created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR s.created_at < sqlc.narg('until'))
+ORDER BY s.created_at DESC
+LIMIT sqlc.arg('limit');
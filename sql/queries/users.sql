@@ -15,4 +15,8 @@ SELECT * FROM users WHERE name = $1;
 SELECT * FROM users;
 
 -- name: DeleteAllUsers :exec
-DELETE FROM users;
\ No newline at end of file
+DELETE FROM users;
+-- name: SetUserTimezone :exec
+UPDATE users
+SET timezone = $1, updated_at = $2
+WHERE id = $3;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- browse: the posts of the followed feeds, newest first
+CREATE INDEX posts_feed_id_published_at_idx ON posts (feed_id, published_at DESC NULLS FIRST);
+CREATE INDEX posts_published_at_idx ON posts (published_at DESC NULLS FIRST);
+
+-- agg: the feed fetched longest ago
+CREATE INDEX feeds_last_fetched_at_idx ON feeds (last_fetched_at ASC NULLS FIRST);
+
+-- fetchlog: a feed's fetches, newest first
+CREATE INDEX feed_fetches_feed_id_started_at_idx ON feed_fetches (feed_id, started_at DESC);
+
+-- +goose Down
+DROP INDEX feed_fetches_feed_id_started_at_idx;
+DROP INDEX feeds_last_fetched_at_idx;
+DROP INDEX posts_published_at_idx;
+DROP INDEX posts_feed_id_published_at_idx;
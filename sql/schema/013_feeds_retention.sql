@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN retention_days INTEGER NULL;
+
+ALTER TABLE feeds
+ADD COLUMN retention_keep INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN retention_keep;
+
+ALTER TABLE feeds
+DROP COLUMN retention_days;
@@ -0,0 +1,16 @@
+-- name: UpsertCategory :one
+INSERT INTO categories (id, created_at, name)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (name) DO UPDATE SET name = excluded.name
+RETURNING *;
+
+-- name: AddPostCategory :exec
+INSERT INTO post_categories (post_id, category_id)
+VALUES (?1, ?2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetCategoriesForPost :many
+SELECT c.* FROM categories c
+JOIN post_categories pc ON c.id = pc.category_id
+WHERE pc.post_id = ?1
+ORDER BY c.name;
@@ -0,0 +1,41 @@
+-- name: CreateFeedFollow :one
+-- SQLite doesn't allow INSERT inside WITH, so the names are looked up in
+-- the RETURNING clause instead
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING
+    id, created_at, updated_at, user_id, feed_id,
+    (SELECT name FROM users WHERE users.id = feed_follows.user_id) as user_name,
+    (SELECT name FROM feeds WHERE feeds.id = feed_follows.feed_id) as feed_name;
+
+-- name: GetFeedFollowsForUser :many
+SELECT ff.id, ff.created_at, ff.updated_at, ff.user_id, ff.feed_id,
+       u.name as user_name,
+       f.name as feed_name,
+       f.url as feed_url,
+       (
+           SELECT COUNT(*) FROM posts p
+           WHERE p.feed_id = ff.feed_id AND NOT EXISTS (
+               SELECT 1 FROM post_reads pr
+               WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+           )
+       ) as unread_count
+FROM feed_follows ff
+JOIN users u ON ff.user_id = u.id
+JOIN feeds f ON ff.feed_id = f.id
+WHERE ff.user_id = ?1
+ORDER BY ff.created_at DESC;
+
+-- name: GetFeedFollowByUserAndFeed :one
+SELECT * FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2;
+
+-- name: DeleteFeedFollow :exec
+DELETE FROM feed_follows
+WHERE id = ?1;
+
+-- name: DeleteFeedFollowByUserAndFeedURL :exec
+DELETE FROM feed_follows
+WHERE feed_follows.user_id = ?1 AND feed_id = (
+    SELECT id FROM feeds WHERE url = ?2
+);
@@ -0,0 +1,18 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT DO NOTHING;
+
+-- name: MarkPostUnread :exec
+DELETE FROM post_reads
+WHERE user_id = ?1 AND post_id = ?2;
+
+-- name: MarkPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT ff.user_id, p.id, ?1
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = ?2
+  AND (?3 IS NULL OR p.feed_id = ?3)
+  AND (?4 IS NULL OR p.published_at < ?4)
+ON CONFLICT DO NOTHING;
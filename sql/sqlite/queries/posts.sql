@@ -0,0 +1,132 @@
+-- name: CreatePost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, published_at_inferred, feed_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, published_at_inferred;
+
+-- name: GetPostsForUser :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.content, p.published_at,
+       p.published_at_inferred, p.feed_id, f.name as feed_name,
+       COALESCE((
+           SELECT string_agg(c.name, ', ' ORDER BY c.name)
+           FROM post_categories pc
+           JOIN categories c ON pc.category_id = c.id
+           WHERE pc.post_id = p.id
+       ), '') as categories,
+       COALESCE((
+           SELECT string_agg(a.name, ', ' ORDER BY a.name)
+           FROM post_authors pa
+           JOIN authors a ON pa.author_id = a.id
+           WHERE pa.post_id = p.id
+       ), '') as authors,
+       EXISTS (
+           SELECT 1 FROM post_reads pr
+           WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+       ) as read
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = ?1
+  AND (NOT ?2 OR NOT EXISTS (
+      SELECT 1 FROM post_reads pr
+      WHERE pr.post_id = p.id AND pr.user_id = ff.user_id
+  ))
+  AND (?3 IS NULL OR EXISTS (
+      SELECT 1 FROM post_categories pc
+      JOIN categories c ON pc.category_id = c.id
+      WHERE pc.post_id = p.id AND c.name = lower(?3)
+  ))
+  AND (?4 IS NULL OR EXISTS (
+      SELECT 1 FROM post_authors pa
+      JOIN authors a ON pa.author_id = a.id
+      WHERE pa.post_id = p.id
+        AND (lower(a.name) = lower(?4) OR lower(a.email) = lower(?4))
+  ))
+  AND (?5 IS NULL OR p.feed_id = ?5)
+  AND (?6 IS NULL OR p.published_at >= ?6)
+  AND (?7 IS NULL OR p.published_at < ?7)
+  AND (NOT ?8 OR EXISTS (
+      SELECT 1 FROM saved_posts s
+      WHERE s.post_id = p.id AND s.user_id = ff.user_id
+  ))
+  -- Only posts after the cursor (?10, ?9) in the published order below,
+  -- where a NULL date sorts first
+  AND (?9 IS NULL
+       OR p.published_at < ?10
+       OR (p.published_at = ?10 AND p.id < ?9)
+       OR (?10 IS NULL AND (p.published_at IS NOT NULL OR p.id < ?9)))
+-- ?11 is 'published', 'fetched' (newest stored first) or 'feed' (by feed
+-- name, then published)
+ORDER BY CASE WHEN ?11 = 'feed' THEN f.name END ASC,
+         CASE WHEN ?11 = 'fetched' THEN p.created_at END DESC,
+         p.published_at DESC NULLS FIRST, p.id DESC
+LIMIT ?13 OFFSET ?12;
+
+-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, published_at_inferred FROM posts
+WHERE url = ?1;
+
+-- name: GetPostsByIDPrefix :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.published_at_inferred
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = ?1
+  AND p.id BETWEEN ?2 AND ?3
+ORDER BY p.id
+LIMIT 2;
+
+-- name: DeletePostsByFeedID :exec
+-- Saved posts are kept
+DELETE FROM posts
+WHERE feed_id = ?1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = posts.id);
+
+-- name: GetPostsCount :one
+SELECT COUNT(*) FROM posts;
+
+-- name: UpdatePostContent :exec
+UPDATE posts
+SET content = ?1, updated_at = ?2
+WHERE id = ?3;
+
+-- name: CountNewerPosts :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = ?1 AND (published_at IS NULL OR published_at > ?2);
+
+-- name: CountExpiredPosts :one
+SELECT COUNT(*) FROM posts AS p
+WHERE p.feed_id = ?1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = p.id)
+  AND ((?2 IS NOT NULL AND p.published_at < ?2)
+    OR (?3 IS NOT NULL AND p.id IN (
+        SELECT k.id FROM posts k
+        WHERE k.feed_id = ?1
+        ORDER BY k.published_at DESC NULLS FIRST, k.id DESC
+        LIMIT -1 OFFSET ?3
+    )));
+
+-- name: DeleteExpiredPosts :execrows
+DELETE FROM posts AS p
+WHERE p.feed_id = ?1
+  AND NOT EXISTS (SELECT 1 FROM saved_posts s WHERE s.post_id = p.id)
+  AND ((?2 IS NOT NULL AND p.published_at < ?2)
+    OR (?3 IS NOT NULL AND p.id IN (
+        SELECT k.id FROM posts k
+        WHERE k.feed_id = ?1
+        ORDER BY k.published_at DESC NULLS FIRST, k.id DESC
+        LIMIT -1 OFFSET ?3
+    )));
+
+-- name: SearchPosts :many
+SELECT p.id, p.title, p.url, p.published_at, p.published_at_inferred, f.name AS feed_name,
+       snippet(posts_search, -1, '⟪', '⟫', ' … ', 20) AS snippet
+FROM posts_search
+JOIN posts p ON p.rowid = posts_search.rowid
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE posts_search MATCH websearch_to_fts5(?1)
+  AND ff.user_id = ?2
+  AND (?3 IS NULL OR p.feed_id = ?3)
+  AND (?4 IS NULL OR p.published_at >= ?4)
+  AND (?5 IS NULL OR p.published_at < ?5)
+ORDER BY bm25(posts_search, 10.0, 5.0, 1.0), p.published_at DESC
+LIMIT ?6;
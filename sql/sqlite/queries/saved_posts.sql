@@ -0,0 +1,65 @@
+-- name: CreateSavedPost :one
+INSERT INTO saved_posts (id, user_id, post_id, created_at, updated_at, url, title, feed_name, published_at, description, content, note)
+SELECT ?1, ?2, p.id, ?3, ?3,
+       p.url, p.title, f.name, p.published_at, p.description, p.content, ?4
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+WHERE p.id = ?5
+RETURNING *;
+
+-- name: GetSavedPost :one
+SELECT * FROM saved_posts
+WHERE user_id = ?1 AND post_id = ?2;
+
+-- name: GetSavedPostByURL :one
+SELECT * FROM saved_posts
+WHERE user_id = ?1 AND url = ?2;
+
+-- name: GetSavedPostsByIDPrefix :many
+SELECT * FROM saved_posts
+WHERE user_id = ?1
+  AND post_id BETWEEN ?2 AND ?3
+ORDER BY post_id
+LIMIT 2;
+
+-- name: SetSavedPostNote :exec
+UPDATE saved_posts
+SET note = ?1, updated_at = ?2
+WHERE id = ?3;
+
+-- name: DeleteSavedPost :exec
+DELETE FROM saved_posts
+WHERE id = ?1;
+
+-- name: AddSavedPostTag :exec
+INSERT INTO saved_post_tags (saved_post_id, tag)
+VALUES (?1, ?2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveSavedPostTag :exec
+DELETE FROM saved_post_tags
+WHERE saved_post_id = ?1 AND tag = ?2;
+
+-- name: GetSavedPosts :many
+-- LIKE ignores case in SQLite, as ILIKE does in Postgres
+SELECT s.id, s.user_id, s.post_id, s.created_at, s.updated_at, s.url, s.title, s.feed_name,
+       s.published_at, s.description, s.content, s.note,
+       COALESCE((
+           SELECT string_agg(t.tag, ', ' ORDER BY t.tag)
+           FROM saved_post_tags t
+           WHERE t.saved_post_id = s.id
+       ), '') as tags
+FROM saved_posts s
+WHERE s.user_id = ?1
+  AND (?2 IS NULL OR EXISTS (
+      SELECT 1 FROM saved_post_tags t
+      WHERE t.saved_post_id = s.id AND t.tag = ?2
+  ))
+  AND (?3 IS NULL OR lower(s.feed_name) = lower(?3))
+  AND (?4 IS NULL
+       OR s.title LIKE '%' || ?4 || '%'
+       OR s.note LIKE '%' || ?4 || '%')
+  AND (?5 IS NULL OR s.created_at >= ?5)
+  AND (?6 IS NULL OR s.created_at < ?6)
+ORDER BY s.created_at DESC
+LIMIT ?7;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- browse: the posts of the followed feeds, newest first
+CREATE INDEX posts_feed_id_published_at_idx ON posts (feed_id, published_at DESC);
+CREATE INDEX posts_published_at_idx ON posts (published_at DESC);
+
+-- agg: the feed fetched longest ago. SQLite sorts NULLs first in ascending
+-- order already.
+CREATE INDEX feeds_last_fetched_at_idx ON feeds (last_fetched_at);
+
+-- fetchlog: a feed's fetches, newest first
+CREATE INDEX feed_fetches_feed_id_started_at_idx ON feed_fetches (feed_id, started_at DESC);
+
+-- +goose Down
+DROP INDEX feed_fetches_feed_id_started_at_idx;
+DROP INDEX feeds_last_fetched_at_idx;
+DROP INDEX posts_published_at_idx;
+DROP INDEX posts_feed_id_published_at_idx;
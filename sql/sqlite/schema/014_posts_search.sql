@@ -0,0 +1,48 @@
+-- +goose Up
+-- Postgres keeps its search index in a tsvector column on posts. SQLite keeps
+-- it in the FTS5 table below instead, and this always-NULL column only keeps
+-- SELECT * on posts returning the same columns as on Postgres.
+ALTER TABLE posts
+ADD COLUMN search TEXT GENERATED ALWAYS AS (NULL) VIRTUAL;
+
+-- The table holds its own plain text copy of each post, made by html_text
+-- (registered by gator), so that markup is neither searched nor cut up in
+-- snippets. Its rowids are those of posts. As html_text only exists inside
+-- gator, posts can only be added or edited through gator.
+CREATE VIRTUAL TABLE posts_search USING fts5(
+    title, description, content,
+    tokenize = 'porter unicode61'
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_search_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_search (rowid, title, description, content)
+    VALUES (new.rowid, new.title, html_text(new.description), html_text(new.content));
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_search_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_search WHERE rowid = old.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_search_update AFTER UPDATE OF title, description, content ON posts BEGIN
+    UPDATE posts_search
+    SET title = new.title, description = html_text(new.description), content = html_text(new.content)
+    WHERE rowid = new.rowid;
+END;
+-- +goose StatementEnd
+
+INSERT INTO posts_search (rowid, title, description, content)
+SELECT rowid, title, html_text(description), html_text(content) FROM posts;
+
+-- +goose Down
+DROP TRIGGER posts_search_update;
+DROP TRIGGER posts_search_delete;
+DROP TRIGGER posts_search_insert;
+DROP TABLE posts_search;
+
+ALTER TABLE posts
+DROP COLUMN search;
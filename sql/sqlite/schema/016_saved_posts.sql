@@ -0,0 +1,32 @@
+-- +goose Up
+-- A save keeps its own copy of the post, and post_id has no foreign key, so
+-- that saves survive their post being deleted along with its feed
+CREATE TABLE saved_posts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    post_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    url TEXT NOT NULL,
+    title TEXT NOT NULL,
+    feed_name TEXT NOT NULL,
+    published_at TIMESTAMP,
+    description TEXT,
+    content TEXT,
+    note TEXT NOT NULL DEFAULT '',
+    UNIQUE (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX saved_posts_post_id_idx ON saved_posts (post_id);
+
+CREATE TABLE saved_post_tags (
+    saved_post_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (saved_post_id, tag),
+    FOREIGN KEY (saved_post_id) REFERENCES saved_posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE saved_post_tags;
+DROP TABLE saved_posts;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- browse breaks ties between posts published at the same time by ID, so
+-- that paging through them with a cursor never skips or repeats one
+DROP INDEX posts_published_at_idx;
+DROP INDEX posts_feed_id_published_at_idx;
+CREATE INDEX posts_feed_id_published_at_idx ON posts (feed_id, published_at DESC, id DESC);
+CREATE INDEX posts_published_at_idx ON posts (published_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX posts_published_at_idx;
+DROP INDEX posts_feed_id_published_at_idx;
+CREATE INDEX posts_feed_id_published_at_idx ON posts (feed_id, published_at DESC);
+CREATE INDEX posts_published_at_idx ON posts (published_at DESC);
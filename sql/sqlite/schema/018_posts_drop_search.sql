@@ -0,0 +1,9 @@
+-- +goose Up
+-- The posts queries list their columns, so the always-NULL search column
+-- added by 014 to keep SELECT * in line with Postgres is no longer needed
+ALTER TABLE posts
+DROP COLUMN search;
+
+-- +goose Down
+ALTER TABLE posts
+ADD COLUMN search TEXT GENERATED ALWAYS AS (NULL) VIRTUAL;
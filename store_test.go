@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/storetest"
+
+	"github.com/google/uuid"
+)
+
+// testDBURLEnv names the variable holding a Postgres connection string to
+// also run the store tests against. Each test gets a schema of its own,
+// dropped when it ends.
+const testDBURLEnv = "GATOR_TEST_DB_URL"
+
+func TestSQLiteStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) database.Store {
+		return openTestState(t, sqliteTestURL(t)).db
+	})
+}
+
+func TestPostgresStore(t *testing.T) {
+	dbURL := os.Getenv(testDBURLEnv)
+	if dbURL == "" {
+		t.Skipf("set %s to run against Postgres", testDBURLEnv)
+	}
+
+	storetest.Run(t, func(t *testing.T) database.Store {
+		return openTestState(t, postgresTestURL(t, dbURL)).db
+	})
+}
+
+// sqliteTestURL returns the URL of a new SQLite database removed when tb
+// ends
+func sqliteTestURL(tb testing.TB) string {
+	return sqliteScheme + filepath.Join(tb.TempDir(), "gator.db")
+}
+
+// postgresTestURL creates a schema in the database at dbURL, dropped when
+// tb ends, and returns a URL whose connections use it
+func postgresTestURL(tb testing.TB, dbURL string) string {
+	tb.Helper()
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	schema := "gator_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		tb.Fatalf("create schema: %v", err)
+	}
+	tb.Cleanup(func() {
+		defer admin.Close()
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			tb.Errorf("drop schema: %v", err)
+		}
+	})
+
+	if !strings.Contains(dbURL, "://") {
+		return fmt.Sprintf("%s search_path=%s", dbURL, schema)
+	}
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		tb.Fatalf("parse %s: %v", testDBURLEnv, err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// openTestState opens and migrates the database at dbURL
+func openTestState(tb testing.TB, dbURL string) *state {
+	tb.Helper()
+	db, dbtx, dialect, err := openDatabase(dbURL)
+	if err != nil {
+		tb.Fatalf("open database: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	if err := runMigrations(db, dialect); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+	return &state{db: database.NewStore(dbtx), conn: db, dialect: dialect}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/config"
+	"github.com/AlexTLDR/gator/internal/database"
+	"github.com/AlexTLDR/gator/internal/htmltext"
+)
+
+// postView is what a browse --format template sees of each post. Times are
+// in the user's time zone, and Published is zero when the feed gave no date.
+type postView struct {
+	ID                string
+	ShortID           string
+	Title             string
+	URL               string
+	FeedName          string
+	Published         time.Time
+	PublishedInferred bool
+	Fetched           time.Time
+	Authors           string
+	Tags              string
+	Read              bool
+	Description       string
+	Content           string
+	Cursor            string
+}
+
+func newPostView(post database.GetPostsForUserRow, loc *time.Location) postView {
+	view := postView{
+		ID:                post.ID.String(),
+		ShortID:           shortID(post.ID),
+		Title:             post.Title,
+		URL:               post.Url,
+		FeedName:          post.FeedName,
+		PublishedInferred: post.PublishedAtInferred,
+		Fetched:           post.CreatedAt.In(loc),
+		Authors:           post.Authors,
+		Tags:              post.Categories,
+		Read:              post.Read,
+		Description:       htmltext.Render(post.Description.String).Text,
+		Content:           htmltext.Render(post.Content.String).Text,
+		Cursor:            formatCursor(post.PublishedAt, post.ID),
+	}
+	if post.PublishedAt.Valid {
+		view.Published = post.PublishedAt.Time.In(loc)
+	}
+	return view
+}
+
+// templateFuncs are the helpers browse --format templates can use besides
+// text/template's own
+var templateFuncs = template.FuncMap{
+	// truncate 80 .Title shortens text to at most that many characters
+	"truncate": func(n int, s string) string {
+		return htmltext.Truncate(s, n)
+	},
+	// wrap 72 .Description wraps text at that width, or the terminal's
+	// with 0
+	"wrap": func(width int, s string) string {
+		if width <= 0 {
+			width = terminalWidth()
+		}
+		return htmltext.Wrap(s, width)
+	},
+	// date "Jan 2 15:04" .Published formats a time with a Go layout, or
+	// returns "" for an unknown one
+	"date": func(layout string, t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	},
+	// domain .URL is the host of a URL without any leading www.
+	"domain": func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimPrefix(u.Hostname(), "www.")
+	},
+}
+
+// parseFormat parses the template browse --format was given, which is
+// either the name of one of the config's templates or a template itself
+func parseFormat(cfg *config.Config, value string) (*template.Template, error) {
+	name := value
+	text, named := cfg.Templates[value]
+	if !named {
+		if !strings.Contains(value, "{{") {
+			return nil, fmt.Errorf("no template named %q in the config (it has %s)", value, templateNames(cfg))
+		}
+		name, text = "format", value
+	}
+
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// templateNames lists the names of the config's templates for messages
+func templateNames(cfg *config.Config) string {
+	if len(cfg.Templates) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(cfg.Templates))
+	for name := range cfg.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// printPostsWithTemplate renders each post with tmpl, one after another,
+// ending each with a newline unless the template already does. Nothing is
+// written if any post fails to render.
+func printPostsWithTemplate(w io.Writer, tmpl *template.Template, posts []database.GetPostsForUserRow, loc *time.Location) error {
+	var b strings.Builder
+	for _, post := range posts {
+		if err := tmpl.Execute(&b, newPostView(post, loc)); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/AlexTLDR/gator/internal/config"
+	"github.com/AlexTLDR/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestParseFormat(t *testing.T) {
+	cfg := &config.Config{Templates: map[string]string{
+		"short":  "{{.Title}}",
+		"broken": "{{.Title",
+	}}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		value    string
+		wantName string
+		wantErr  string
+	}{
+		{"named", cfg, "short", "short", ""},
+		{"inline", cfg, "{{.Title}} <{{.URL}}>", "format", ""},
+		{"inline with the helpers", cfg, `{{truncate 10 .Title}} {{wrap 72 .Description}} {{date "Jan 2" .Published}} {{domain .URL}}`, "format", ""},
+		{"unknown name", cfg, "long", "", `no template named "long" in the config (it has broken, short)`},
+		{"unknown name without templates", &config.Config{}, "short", "", `no template named "short" in the config (it has none)`},
+		{"broken named", cfg, "broken", "", "unclosed action"},
+		{"broken inline", cfg, "{{.Title", "", "unclosed action"},
+		{"unknown function", cfg, "{{upper .Title}}", "", `function "upper" not defined`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseFormat(tt.cfg, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFormat(%q) error = %v, want one containing %q", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFormat(%q): %v", tt.value, err)
+			}
+			if tmpl.Name() != tt.wantName {
+				t.Errorf("parseFormat(%q) named the template %q, want %q", tt.value, tmpl.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Setenv("COLUMNS", "10")
+	at := time.Date(2026, 3, 1, 9, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+		data any
+		want string
+	}{
+		{"truncate at a word", "{{truncate 14 .}}", "Hello brave new world", "Hello brave..."},
+		{"truncate short text", "{{truncate 80 .}}", "Hello", "Hello"},
+		{"wrap", "{{wrap 10 .}}", "one two three four", "one two\nthree four"},
+		{"wrap keeps line breaks", "{{wrap 10 .}}", "one\ntwo", "one\ntwo"},
+		{"wrap 0 at the terminal's width", "{{wrap 0 .}}", "one two three four", "one two\nthree four"},
+		{"date", `{{date "Jan 2 15:04" .}}`, at, "Mar 1 09:05"},
+		{"date unknown", `{{date "Jan 2 15:04" .}}`, time.Time{}, ""},
+		{"domain", "{{domain .}}", "https://go.dev/blog/go1.24", "go.dev"},
+		{"domain without www.", "{{domain .}}", "https://www.example.com:8080/a?b=c", "example.com"},
+		{"domain of an invalid URL", "{{domain .}}", "http://[::1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(templateFuncs).Parse(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := tmpl.Execute(&b, tt.data); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintPostsWithTemplate(t *testing.T) {
+	est := time.FixedZone("EST", -5*3600)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	posts := []database.GetPostsForUserRow{
+		{
+			ID:          uuid.MustParse("0192f3a4-5b6c-7d8e-9f01-23456789abcd"),
+			CreatedAt:   at,
+			Title:       "Post one",
+			Url:         "https://www.news.example/1",
+			Description: sql.NullString{String: "<p>Fish &amp; chips</p>", Valid: true},
+			PublishedAt: sql.NullTime{Time: at, Valid: true},
+			FeedName:    "news",
+			Categories:  "food",
+		},
+		{
+			ID:        uuid.MustParse("0192f3a4-5b6c-7d8e-9f01-000000000002"),
+			CreatedAt: at,
+			Title:     "Post two",
+			Url:       "https://news.example/2",
+			FeedName:  "news",
+			Read:      true,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "a line per post",
+			text: `{{.ShortID}} {{.Title}} ({{domain .URL}}) {{date "2006-01-02 15:04" .Published}}`,
+			want: "0192f3a4 Post one (news.example) 2026-03-01 07:00\n0192f3a4 Post two (news.example) \n",
+		},
+		{
+			name: "a template ending in a newline",
+			text: "{{.Title}}: {{.Description}}\n",
+			want: "Post one: Fish & chips\nPost two: \n",
+		},
+		{
+			name: "every field",
+			text: "{{.ID}}|{{.FeedName}}|{{.Tags}}|{{.Read}}|{{.PublishedInferred}}|{{.Fetched.Format \"15:04\"}}|{{.Cursor}}",
+			want: "0192f3a4-5b6c-7d8e-9f01-23456789abcd|news|food|false|false|07:00|2026-03-01T12:00:00Z,0192f3a4-5b6c-7d8e-9f01-23456789abcd\n" +
+				"0192f3a4-5b6c-7d8e-9f01-000000000002|news||true|false|07:00|" + nullCursorDate + ",0192f3a4-5b6c-7d8e-9f01-000000000002\n",
+		},
+		{
+			// The first post renders, but nothing is printed
+			name:    "a post failing to render",
+			text:    "{{if .Read}}{{.Missing}}{{end}}{{.Title}}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseFormat(&config.Config{}, tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			err = printPostsWithTemplate(&buf, tmpl, posts, est)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("printPostsWithTemplate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printPostsWithTemplate wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBrowseNamedFormat renders browse with a template from the config, and
+// refuses a name the config doesn't have
+func TestBrowseNamedFormat(t *testing.T) {
+	s := openTestState(t, sqliteTestURL(t))
+	s.output = outputText
+	s.cfg = &config.Config{Templates: map[string]string{"short": "{{.FeedName}}: {{.Title}}"}}
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	user, err := s.db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := s.db.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "news", Url: "https://news.example/feed.xml", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: user.ID, FeedID: feed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, title := range []string{"Post one", "Post two"} {
+		at := now.Add(-time.Duration(i) * time.Hour)
+		_, err := s.db.CreatePost(ctx, database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   at,
+			UpdatedAt:   at,
+			Title:       title,
+			Url:         "https://news.example/" + title,
+			PublishedAt: sql.NullTime{Time: at, Valid: true},
+			FeedID:      feed.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := captureStdout(t, func() error {
+		return handlerBrowse(s, command{Name: "browse", Args: []string{"--format", "short"}}, user)
+	})
+	if want := "news: Post one\nnews: Post two\n"; out != want {
+		t.Errorf("browse --format short printed %q, want %q", out, want)
+	}
+
+	err = handlerBrowse(s, command{Name: "browse", Args: []string{"--format", "long"}}, user)
+	if want := `invalid --format: no template named "long" in the config (it has short)`; err == nil || err.Error() != want {
+		t.Errorf("browse --format long = %v, want %q", err, want)
+	}
+}
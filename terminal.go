@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+const defaultTerminalWidth = 80
+
+// terminalWidth returns the width of the terminal stdout is attached to,
+// falling back to $COLUMNS and then to 80 columns when output is piped
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// isTerminal reports whether stdout is a terminal rather than a pipe or file
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}